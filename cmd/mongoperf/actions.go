@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"mongoperf/internal/client"
+	"mongoperf/internal/client/query"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newCommandActions() *cobra.Command {
+	var (
+		jsonSchema bool
+	)
+	cmd := &cobra.Command{
+		Use:   "actions [name]",
+		Short: "List the query actions and their Meta.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// VALIDATE COMMAND LINE ARGS
+			actions := query.Actions
+			if len(args) == 1 {
+				action, err := findAction(args[0])
+				if err != nil {
+					return err
+				}
+				actions = []query.Action{action}
+			}
+
+			// GENERATE JSON SCHEMA
+			if jsonSchema {
+				schema := client.ActionsSchema()
+				if len(args) == 1 {
+					s, err := client.ActionSchema(actions[0])
+					if err != nil {
+						return err
+					}
+					s.Schema = client.JSONSchemaDraft
+					schema = s
+				}
+				b, err := json.MarshalIndent(schema, "", "  ")
+				if err != nil {
+					return err
+				}
+				writeOut(string(b))
+				return nil
+			}
+
+			// GENERATE DOC
+			for i, a := range actions {
+				if i > 0 {
+					writeOut("")
+				}
+				if err := client.GenerateActionDoc(defaultOutput, a); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonSchema, "json-schema", false, "Print the JSON Schema of a query, or of the Meta of the action when one is provided.")
+	return cmd
+}
+
+func findAction(name string) (query.Action, error) {
+	names := make([]string, 0, len(query.Actions))
+	for _, a := range query.Actions {
+		if string(a) == name {
+			return a, nil
+		}
+		names = append(names, string(a))
+	}
+	return "", fmt.Errorf("action must be one of: %v", strings.Join(names, ", "))
+}
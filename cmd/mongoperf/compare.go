@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"mongoperf/internal/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newCommandCompare() *cobra.Command {
+	var (
+		tolerance float64
+	)
+	cmd := &cobra.Command{
+		Use:   "compare [baseline.json] [candidate.json]",
+		Short: "Compare two JSON reports.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// VALIDATE COMMAND LINE ARGS
+			if tolerance < 0 {
+				return fmt.Errorf("tolerance must be greater than or equal to 0")
+			}
+
+			// READ REPORTS
+			baseline, err := client.ReadReportFile(args[0])
+			if err != nil {
+				return err
+			}
+			candidate, err := client.ReadReportFile(args[1])
+			if err != nil {
+				return err
+			}
+
+			// COMPARE
+			comparison := client.CompareReports(baseline, candidate, tolerance)
+			if err := client.GenerateComparison(defaultOutput, comparison); err != nil {
+				return err
+			}
+			if n := comparison.Regressions(); n > 0 {
+				cmd.SilenceUsage = true
+				return &exitCodeError{
+					code: exitRegression,
+					err:  fmt.Errorf("%d regression(s) found", n),
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Float64Var(&tolerance, "tolerance", 10, "Percentage by which a metric can worsen before being reported as a regression.")
+	return cmd
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mongoperf/internal/client"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// demoSeedScenario inserts the documents read, updated
+// and deleted by the demo scenario.
+const demoSeedScenario = `
+Database: %[1]v
+Collection: %[2]v
+Repeat: 50
+Queries:
+- Name: seed
+  Action: InsertMany
+  Meta:
+    Data:
+    - {kind: trainer, name: Ash, age: 10, city: Pallet Town, badges: 0}
+    - {kind: trainer, name: Misty, age: 10, city: Cerulean City, badges: 0}
+    - {kind: trainer, name: Brock, age: 15, city: Pewter City, badges: 0}
+    - {kind: trainer, name: Gary, age: 10, city: Pallet Town, badges: 0}
+`
+
+// demoScenario runs every kind of action against the seeded documents.
+const demoScenario = `
+Database: %[1]v
+Collection: %[2]v
+Parallel: %[3]d
+Repeat: %[4]d
+Queries:
+- Name: insert-trainer
+  Action: InsertOne
+  Meta:
+    Data: {kind: trainer, name: Red, age: 11, city: Pallet Town, badges: 0}
+- Name: award-badge
+  Action: UpdateOneAction
+  Meta:
+    Filter: {kind: trainer, city: Pallet Town}
+    Data: {$inc: {badges: 1}}
+- Name: find-trainer
+  Action: FindOneAction
+  Meta:
+    Filter: {name: Misty}
+- Name: find-pallet-town
+  Action: Find
+  Meta:
+    Filter: {city: Pallet Town}
+    Options: {Limit: 20}
+- Name: retire-trainer
+  Action: DeleteOne
+  Meta:
+    Filter: {kind: trainer, name: Red}
+`
+
+func newCommandDemo() *cobra.Command {
+	var (
+		uri      string
+		isDebug  bool
+		database string
+		parallel []int
+		repeat   int
+	)
+	cmd := &cobra.Command{
+		Use:   "demo",
+		Short: "Run small demo that inserts, update and delete entries.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// VALIDATE COMMAND LINE ARGS
+			if uri == "" {
+				uri = "mongodb://localhost:27017"
+			}
+			if len(parallel) == 0 {
+				return fmt.Errorf("parallel must not be empty")
+			}
+			for _, p := range parallel {
+				if p < 1 {
+					return fmt.Errorf("parallel must be greater than or equal to 1")
+				}
+			}
+			if repeat < 1 {
+				return fmt.Errorf("repeat must be greater than or equal to 1")
+			}
+
+			// CREATE LOGGER
+			logger := logrus.New()
+			if isDebug {
+				logger.SetLevel(logrus.DebugLevel)
+			}
+
+			// START CLIENT
+			logger.Printf("connecting to: %v", uri)
+			c, err := client.New(context.TODO(), uri, client.WithLogger(logger))
+			if err != nil {
+				return err
+			}
+			defer c.Close(context.TODO())
+
+			// SETUP INTERRUPT HANDLER
+			interruptCh := getInterruptCh()
+			ctx, cancelCtx := context.WithCancel(context.Background())
+			defer cancelCtx()
+
+			go func() {
+				select {
+				case <-interruptCh:
+					cancelCtx()
+				case <-ctx.Done():
+				}
+			}()
+
+			// SEED COLLECTION
+			collection := fmt.Sprintf("demo_%d", time.Now().Unix())
+			seed, err := client.ParseScenario([]byte(fmt.Sprintf(demoSeedScenario, database, collection)), nil)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				logger.Infof("dropping collection: %v", collection)
+				if err := c.DropCollection(context.TODO(), seed); err != nil {
+					logger.Errorf("could not drop collection %v: %v", collection, err)
+				}
+			}()
+			logger.Infof("seeding collection: %v", collection)
+			if _, err := c.RunScenario(ctx, seed); err != nil {
+				return err
+			}
+
+			// RUN SCENARIOS
+			for _, p := range parallel {
+				if ctx.Err() != nil {
+					break
+				}
+				scenario, err := client.ParseScenario([]byte(fmt.Sprintf(demoScenario, database, collection, p, repeat)), nil)
+				if err != nil {
+					return err
+				}
+				logger.Infof("running demo with %d worker(s)", p)
+				results, err := c.RunScenario(ctx, scenario)
+				if err != nil {
+					return err
+				}
+				report := client.NewReport(cmd.Parent().Version, uri, scenario, results)
+				if err := client.WriteReport(defaultOutput, report, client.TextFormat); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&uri, "uri", "", "MongoDB URI connection string.")
+	cmd.Flags().BoolVar(&isDebug, "debug", false, "Set logger level to DEBUG.")
+	cmd.Flags().StringVar(&database, "database", "mongoperf", "Database in which the temporary collection is created.")
+	cmd.Flags().IntSliceVar(&parallel, "parallel", []int{1, 4}, "Parallelism levels at which the demo is run.")
+	cmd.Flags().IntVar(&repeat, "repeat", 200, "Number of times the demo queries are sent at each parallelism level.")
+	return cmd
+}
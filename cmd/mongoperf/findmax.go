@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mongoperf/internal/client"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// findMaxConfig holds the scenario command flags used by find-max.
+type findMaxConfig struct {
+	uri        string
+	isDebug    bool
+	partial    bool
+	format     string
+	outputFile string
+	options    client.FindMaxOptions
+}
+
+// runFindMax searches the maximum sustainable rate of the
+// scenario, then writes the report of its steps.
+func runFindMax(cmd *cobra.Command, scenario *client.Scenario, cfg findMaxConfig) error {
+	// VALIDATE COMMAND LINE ARGS
+	if err := checkSingleRunFlags(cmd, "find-max"); err != nil {
+		return err
+	}
+	if !isFindMaxFormat(cfg.format) {
+		return fmt.Errorf("output-format must be one of: %v with find-max", strings.Join(client.FindMaxFormats, ", "))
+	}
+	opts := cfg.options
+	if opts.P99Target <= 0 {
+		return fmt.Errorf("p99-target must be greater than 0 with find-max")
+	}
+	if opts.MaxErrorRate < 0 || opts.MaxErrorRate > 1 {
+		return fmt.Errorf("max-error-rate must be between 0 and 1")
+	}
+	if opts.StartRate <= 0 {
+		return fmt.Errorf("start-rate must be greater than 0")
+	}
+	if opts.StepDuration <= 0 {
+		return fmt.Errorf("step-duration must be greater than 0")
+	}
+	if opts.Precision <= 0 {
+		return fmt.Errorf("precision must be greater than 0")
+	}
+	if opts.MaxSteps < 1 {
+		return fmt.Errorf("max-steps must be greater than or equal to 1")
+	}
+	if opts.Cooldown < 0 {
+		return fmt.Errorf("cooldown must be greater than or equal to 0")
+	}
+
+	// CREATE LOGGER
+	logger := logrus.New()
+	if cfg.isDebug {
+		logger.SetLevel(logrus.DebugLevel)
+	}
+
+	// BUILD QUERIES
+	if _, err := client.BuildQueriers(scenario); err != nil && !cfg.partial {
+		return fmt.Errorf("%v (use --allow-partial to run the other queries)", err)
+	}
+
+	// START CLIENT
+	logger.Printf("connecting to: %v", cfg.uri)
+	c, err := client.New(context.TODO(), cfg.uri,
+		client.WithLogger(logger),
+		client.WithMaxPoolSize(scenario.MaxPoolSize),
+		client.WithMinPoolSize(scenario.MinPoolSize),
+		client.WithMaxConnIdleTime(scenario.MaxConnIdleTime),
+		client.WithAllowPartial(cfg.partial),
+	)
+	if err != nil {
+		return err
+	}
+	defer c.Close(context.TODO())
+
+	// SETUP INTERRUPT HANDLER
+	interruptCh := getInterruptCh()
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	go func() {
+		select {
+		case <-interruptCh:
+			cancelCtx()
+		case <-ctx.Done():
+		}
+	}()
+
+	// FIND MAX RATE
+	report, err := c.FindMaxRate(ctx, scenario, opts)
+	if err != nil {
+		return err
+	}
+
+	// GENERATE REPORT
+	report.Version = cmd.Parent().Version
+	report.URI = client.RedactURI(cfg.uri)
+	if cfg.outputFile == "" {
+		return client.WriteFindMaxReport(defaultOutput, report, cfg.format)
+	}
+	f, err := os.Create(cfg.outputFile)
+	if err != nil {
+		return err
+	}
+	if err := client.WriteFindMaxReport(f, report, cfg.format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func isFindMaxFormat(format string) bool {
+	for _, f := range client.FindMaxFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mongoperf/internal/client"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newCommandInit() *cobra.Command {
+	var (
+		uri        string
+		isDebug    bool
+		tmplName   string
+		database   string
+		collection string
+		fromColl   string
+		sampleSize int
+		force      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "init [scenario-file]",
+		Short: "Write a starter scenario file.",
+		Long:  "Write a starter scenario file, or print it when no file is provided.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// VALIDATE COMMAND LINE ARGS
+			if !isScenarioTemplate(tmplName) {
+				return fmt.Errorf("template must be one of: %v", strings.Join(client.ScenarioTemplates, ", "))
+			}
+			if sampleSize < 1 {
+				return fmt.Errorf("sample-size must be greater than or equal to 1")
+			}
+			if uri == "" {
+				uri = "mongodb://localhost:27017"
+			}
+			var outputFile string
+			if len(args) == 1 {
+				outputFile = args[0]
+				if _, err := os.Stat(outputFile); err == nil && !force {
+					return fmt.Errorf("%v already exists (use --force to overwrite it)", outputFile)
+				}
+			}
+
+			// SAMPLE COLLECTION
+			sample := client.DefaultScenarioSample(database, collection)
+			if fromColl != "" {
+				logger := logrus.New()
+				if isDebug {
+					logger.SetLevel(logrus.DebugLevel)
+				}
+				logger.Printf("connecting to: %v", uri)
+				c, err := client.New(context.TODO(), uri, client.WithLogger(logger))
+				if err != nil {
+					return err
+				}
+				defer c.Close(context.TODO())
+				if !cmd.Flags().Changed("collection") {
+					collection = fromColl
+				}
+				sample, err = c.SampleCollection(context.TODO(), database, fromColl, sampleSize)
+				if err != nil {
+					return err
+				}
+				sample.Collection = collection
+			}
+
+			// GENERATE SCENARIO
+			write := func(w io.Writer) error {
+				return client.GenerateScenario(w, tmplName, sample)
+			}
+			if outputFile == "" {
+				return write(defaultOutput)
+			}
+			f, err := os.Create(outputFile)
+			if err != nil {
+				return err
+			}
+			if err := write(f); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			writeOut(fmt.Sprintf("wrote %v", outputFile))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&uri, "uri", "", "MongoDB URI connection string, used with --from-collection.")
+	cmd.Flags().BoolVar(&isDebug, "debug", false, "Set logger level to DEBUG.")
+	cmd.Flags().StringVar(&tmplName, "template", client.MixedTemplate, fmt.Sprintf("Scenario template (%v).", strings.Join(client.ScenarioTemplates, ", ")))
+	cmd.Flags().StringVar(&database, "database", "mongoperf", "Database of the scenario.")
+	cmd.Flags().StringVar(&collection, "collection", "mongoperf", "Collection of the scenario. Defaults to the sampled collection with --from-collection.")
+	cmd.Flags().StringVar(&fromColl, "from-collection", "", "Infer the queries from documents sampled from this collection of the database.")
+	cmd.Flags().IntVar(&sampleSize, "sample-size", 100, "Number of documents sampled with --from-collection.")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite the scenario file if it exists.")
+	return cmd
+}
+
+func isScenarioTemplate(name string) bool {
+	for _, t := range client.ScenarioTemplates {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
@@ -12,6 +12,24 @@ var (
 	defaultOutput = os.Stdout
 )
 
+// Exit codes .
+const (
+	exitError           = 1
+	exitThresholdBreach = 3
+	exitRegression      = 4
+)
+
+// exitCodeError is returned by commands
+// which must exit with a specific code.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
 func writeOut(line string) {
 	fmt.Fprintln(defaultOutput, line)
 }
@@ -21,9 +39,18 @@ func newCommandRoot() *cobra.Command {
 		Use:     "mongoperf",
 		Short:   "Run performance tests scenarios on a mongodb instance or cluster.",
 		Version: "0.1.1",
+		// errors are written by main, which leaves out
+		// those of the commands exiting with a specific code
+		SilenceErrors: true,
 	}
 	cmd.AddCommand(
+		newCommandDemo(),
+		newCommandInit(),
 		newCommandScenario(),
+		newCommandSchema(),
+		newCommandActions(),
+		newCommandCompare(),
+		newCommandValidate(),
 	)
 	return cmd
 }
@@ -36,7 +63,11 @@ func Execute() error {
 
 func main() {
 	if err := Execute(); err != nil {
-		writeOut(err.Error())
-		os.Exit(1)
+		// the commands log the reason of the exit code
+		if e, ok := err.(*exitCodeError); ok {
+			os.Exit(e.code)
+		}
+		fmt.Fprintln(loggerOutput, "Error:", err)
+		os.Exit(exitError)
 	}
 }
@@ -2,9 +2,16 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"mongoperf/internal/client"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -12,66 +19,413 @@ import (
 
 func newCommandScenario() *cobra.Command {
 	var (
-		uri     string
-		isDebug bool
+		uri         string
+		isDebug     bool
+		serverStats bool
+		explainAll  bool
+		profLevel   int
+		profSlowMS  int
+		perWorker   bool
+		format      string
+		outputFile  string
+		tmplFile    string
+		metricsAddr string
+		sinks       []string
+		sinkEvery   time.Duration
+		traceFile   string
+		traceFormat string
+		live        bool
+		partial     bool
+		set         []string
+		sweeps      []string
+		cooldown    time.Duration
+		reset       bool
+		findMax     bool
+		findMaxOpts client.FindMaxOptions
 	)
 	cmd := &cobra.Command{
 		Use:   "scenario [scenario-file]",
 		Short: "Run a client.",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// PARSE CONFIG
+			// READ CONFIG
 			cfgFile := args[0]
-			scenario, err := client.ParseScenarioFile(cfgFile)
+			params, err := client.ParseParams(set)
+			if err != nil {
+				return err
+			}
+			b, err := ioutil.ReadFile(cfgFile)
 			if err != nil {
 				return err
 			}
-
-			// VALIDATE COMMAND LINE ARGS
 			if uri == "" {
 				uri = "mongodb://localhost:27017"
 			}
 
+			// RUN SWEEP
+			// every combination is parsed by the sweep, as the
+			// parameters it sets may have no default
+			matrix, err := client.ParseSweep(sweeps)
+			if err != nil {
+				return err
+			}
+			fileMatrix, err := client.ScenarioMatrix(b)
+			if err != nil {
+				return err
+			}
+			for k, values := range fileMatrix {
+				if _, ok := matrix[k]; !ok {
+					matrix[k] = values
+				}
+			}
+			if len(matrix) > 0 {
+				return runSweep(cmd, sweepConfig{
+					config:     b,
+					set:        params,
+					matrix:     matrix,
+					uri:        uri,
+					isDebug:    isDebug,
+					partial:    partial,
+					format:     format,
+					outputFile: outputFile,
+					cooldown:   cooldown,
+					reset:      reset,
+				})
+			}
+
+			// PARSE CONFIG
+			scenario, err := client.ParseScenario(b, params)
+			if err != nil {
+				return err
+			}
+
+			// FIND MAX RATE
+			if findMax {
+				findMaxOpts.Cooldown = cooldown
+				return runFindMax(cmd, scenario, findMaxConfig{
+					uri:        uri,
+					isDebug:    isDebug,
+					partial:    partial,
+					format:     format,
+					outputFile: outputFile,
+					options:    findMaxOpts,
+				})
+			}
+
+			// VALIDATE COMMAND LINE ARGS
+			if sinkEvery <= 0 {
+				return fmt.Errorf("sink-interval must be greater than 0")
+			}
+			if !isReportFormat(format) {
+				return fmt.Errorf("output-format must be one of: %v", strings.Join(client.ReportFormats, ", "))
+			}
+			var reportTmpl string
+			if tmplFile != "" {
+				if cmd.Flags().Changed("output-format") {
+					return fmt.Errorf("output-format and report-template are mutually exclusive")
+				}
+				b, err := ioutil.ReadFile(tmplFile)
+				if err != nil {
+					return err
+				}
+				reportTmpl = string(b)
+			}
+			if traceFile != "" && !cmd.Flags().Changed("trace-format") && strings.HasSuffix(traceFile, ".csv") {
+				traceFormat = client.CSVTraceFormat
+			}
+			if traceFormat != client.JSONLTraceFormat && traceFormat != client.CSVTraceFormat {
+				return fmt.Errorf("trace-format must be one of: %v, %v", client.JSONLTraceFormat, client.CSVTraceFormat)
+			}
+
 			// CREATE LOGGER
 			logger := logrus.New()
 			if isDebug {
 				logger.SetLevel(logrus.DebugLevel)
 			}
 
+			// BUILD QUERIES
+			if _, err := client.BuildQueriers(scenario); err != nil && !partial {
+				return fmt.Errorf("%v (use --allow-partial to run the other queries)", err)
+			}
+
+			// CREATE CLIENT OPTIONS
+			clientOptions := []client.Option{
+				client.WithLogger(logger),
+				client.WithMaxPoolSize(scenario.MaxPoolSize),
+				client.WithMinPoolSize(scenario.MinPoolSize),
+				client.WithMaxConnIdleTime(scenario.MaxConnIdleTime),
+				client.WithAllowPartial(partial),
+			}
+			var metrics *client.PrometheusMetrics
+			if metricsAddr != "" {
+				metrics = client.NewPrometheusMetrics()
+				clientOptions = append(clientOptions, client.WithObserver(metrics))
+			}
+			var reporter *client.IntervalReporter
+			if len(sinks) > 0 {
+				var metricsSinks []client.MetricsSink
+				for _, sink := range sinks {
+					ms, err := client.ParseSink(sink)
+					if err != nil {
+						return err
+					}
+					defer func() {
+						if err := ms.Close(); err != nil {
+							logger.Errorf("closing sink: %v", err)
+						}
+					}()
+					metricsSinks = append(metricsSinks, ms)
+				}
+				reporter = client.NewIntervalReporter(sinkEvery, logger, metricsSinks...)
+				clientOptions = append(clientOptions, client.WithObserver(reporter))
+			}
+			var monitor *client.LiveMonitor
+			if live {
+				monitor = client.NewLiveMonitor(defaultOutput, isTerminal(defaultOutput), logger)
+				clientOptions = append(clientOptions, client.WithObserver(monitor))
+			}
+			var tracer *client.TraceWriter
+			if traceFile != "" {
+				f, err := os.Create(traceFile)
+				if err != nil {
+					return err
+				}
+				tracer, err = client.NewTraceWriter(f, traceFormat)
+				if err != nil {
+					f.Close()
+					return err
+				}
+				// closed after the run, or on early returns
+				defer tracer.Close()
+				clientOptions = append(clientOptions, client.WithObserver(tracer))
+			}
+
 			// START CLIENT
 			logger.Printf("connecting to: %v", uri)
-			c, err := client.New(context.TODO(), uri, client.WithLogger(logger))
+			c, err := client.New(context.TODO(), uri, clientOptions...)
 			if err != nil {
 				return err
 			}
+			defer c.Close(context.TODO())
 
 			// SETUP INTERRUPT HANDLER
 			interruptCh := getInterruptCh()
 			ctx, cancelCtx := context.WithCancel(context.Background())
+			defer cancelCtx()
 
 			go func() {
 				select {
 				case <-interruptCh:
 					cancelCtx()
+				case <-ctx.Done():
 				}
 			}()
 
+			// CAPTURE SERVER STATS
+			var before *client.ServerSnapshot
+			if serverStats {
+				before = c.ServerSnapshot(context.TODO(), scenario)
+			}
+
+			// ENABLE PROFILER
+			// entries are read back from the time of the server
+			var profileSince time.Time
+			if profLevel > 0 {
+				restore, err := c.EnableProfiler(context.TODO(), scenario, profLevel, profSlowMS)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					if err := restore(context.TODO()); err != nil {
+						logger.Errorf("could not restore profiling level: %v", err)
+					}
+				}()
+				profileSince, err = c.ServerTime(context.TODO())
+				if err != nil {
+					return err
+				}
+			}
+
+			// SERVE METRICS
+			if metrics != nil {
+				stopMetrics, err := serveMetrics(metricsAddr, metrics, logger)
+				if err != nil {
+					return err
+				}
+				defer stopMetrics()
+			}
+
 			// RUN SCENARIO
+			if reporter != nil {
+				reporter.Start()
+			}
+			if monitor != nil {
+				monitor.Start(c.QueueDepth)
+			}
 			queryResults, err := c.RunScenario(ctx, scenario)
+			if reporter != nil {
+				reporter.Stop()
+			}
+			if monitor != nil {
+				monitor.Stop()
+			}
+			if tracer != nil {
+				if err := tracer.Close(); err != nil {
+					logger.Errorf("writing trace file: %v", err)
+				}
+				if n := tracer.Dropped(); n > 0 {
+					logger.Warnf("trace file: dropped %d record(s) because the writer could not keep up", n)
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			var serverDeltas []client.ServerStatDelta
+			if serverStats {
+				after := c.ServerSnapshot(context.TODO(), scenario)
+				serverDeltas = client.DiffServerSnapshots(before, after)
+			}
+
+			// EXPLAIN QUERIES
+			explains, err := c.ExplainScenario(context.TODO(), scenario, explainAll)
+			if err != nil {
+				return err
+			}
+
+			// READ PROFILER
+			var profiles []*client.ReportProfile
+			if profLevel > 0 {
+				profiles, err = c.ProfileStats(context.TODO(), scenario, profileSince)
+				if err != nil {
+					return err
+				}
+			}
 
 			// GENERATE REPORT
 			report := client.NewReport(cmd.Parent().Version, uri, scenario, queryResults)
-			if err := client.GenerateReport(defaultOutput, report); err != nil {
+			poolStats := c.PoolStats()
+			if perWorker {
+				report.Workers = client.NewReportWorkers(queryResults)
+			}
+			report.Pool = &poolStats
+			report.Server = serverDeltas
+			report.Explains = client.NewReportExplains(explains)
+			report.Profiles = profiles
+			if err := writeReport(report, format, reportTmpl, outputFile); err != nil {
 				return err
 			}
+
+			// EVALUATE THRESHOLDS
+			if n := len(report.Breaches); n > 0 {
+				for _, b := range report.Breaches {
+					logger.Errorf("threshold breached: %v: %v", b.Query, b.Message)
+				}
+				cmd.SilenceUsage = true
+				return &exitCodeError{
+					code: exitThresholdBreach,
+					err:  fmt.Errorf("%d threshold(s) breached", n),
+				}
+			}
 			return nil
 		},
 	}
 	cmd.Flags().StringVar(&uri, "uri", "", "MongoDB URI connection string.")
 	cmd.Flags().BoolVar(&isDebug, "debug", false, "Set logger level to DEBUG.")
+	cmd.Flags().BoolVar(&serverStats, "server-stats", false, "Capture serverStatus, dbStats and collStats before and after the run.")
+	cmd.Flags().BoolVar(&explainAll, "explain", false, "Explain every read query, regardless of its Explain setting.")
+	cmd.Flags().StringArrayVar(&set, "set", nil, "Set a parameter of the scenario, formatted as key=value. Overrides its Params and the environment. Can be repeated.")
+	cmd.Flags().StringArrayVar(&sweeps, "sweep", nil, "Run the scenario once per combination of values, formatted as key=value,value (e.g. Parallel=1,2,4,8). Keys are Parallel, BufferSize, Repeat, Rate, MaxPoolSize, MinPoolSize or parameters. Can be repeated.")
+	cmd.Flags().DurationVar(&cooldown, "cooldown", 0, "Time to wait between the runs of a sweep or the steps of find-max.")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Drop the collection between the runs of a sweep.")
+	cmd.Flags().BoolVar(&findMax, "find-max", false, "Search the highest rate at which the scenario holds the p99-target and max-error-rate SLO.")
+	cmd.Flags().DurationVar(&findMaxOpts.P99Target, "p99-target", 0, "p99 latency the steps of find-max must hold (e.g. 20ms).")
+	cmd.Flags().Float64Var(&findMaxOpts.MaxErrorRate, "max-error-rate", 0.01, "Fraction of failed queries the steps of find-max can have, between 0 and 1.")
+	cmd.Flags().Float64Var(&findMaxOpts.StartRate, "start-rate", 100, "Queries per second sent by the first step of find-max.")
+	cmd.Flags().DurationVar(&findMaxOpts.StepDuration, "step-duration", 30*time.Second, "How long every step of find-max runs.")
+	cmd.Flags().Float64Var(&findMaxOpts.Precision, "precision", 5, "Percentage by which the rates which held and broke the SLO can differ when find-max stops.")
+	cmd.Flags().IntVar(&findMaxOpts.MaxSteps, "max-steps", 20, "Maximum number of steps of find-max.")
+	cmd.Flags().BoolVar(&partial, "allow-partial", false, "Run the queries which can be built when others cannot, instead of refusing to start.")
+	cmd.Flags().BoolVar(&perWorker, "per-worker", false, "Add a per worker breakdown to the report.")
+	cmd.Flags().StringVar(&format, "output-format", client.TextFormat, fmt.Sprintf("Report format (%v).", strings.Join(client.ReportFormats, ", ")))
+	cmd.Flags().StringVar(&tmplFile, "report-template", "", "Render the report using this text/template file.")
+	cmd.Flags().StringVar(&outputFile, "output-file", "", "Write the report to this file instead of stdout.")
+	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Serve Prometheus metrics on this address (e.g. :9090) during the run.")
+	cmd.Flags().StringArrayVar(&sinks, "sink", nil, "Push interval metrics to a sink formatted as kind=target (influx=udp://host:8089, influx=http://host:8086/write?db=mongoperf, influx=metrics.txt, statsd=host:8125). Can be repeated.")
+	cmd.Flags().DurationVar(&sinkEvery, "sink-interval", 10*time.Second, "Interval at which metrics are pushed to the sinks.")
+	cmd.Flags().BoolVar(&live, "live", false, "Show the progress of the run every second. Logged when stdout is not a terminal.")
+	cmd.Flags().StringVar(&traceFile, "trace-file", "", "Write every completed query to this file. Records are dropped, and counted, when the writer cannot keep up.")
+	cmd.Flags().StringVar(&traceFormat, "trace-format", client.JSONLTraceFormat, fmt.Sprintf("Trace file format (%v, %v). Defaults to %v when the trace file ends with .csv.", client.JSONLTraceFormat, client.CSVTraceFormat, client.CSVTraceFormat))
+	cmd.Flags().IntVar(&profLevel, "profile-level", 0, "Enable the database profiler using this level (1 or 2) during the run.")
+	cmd.Flags().IntVar(&profSlowMS, "profile-slowms", 100, "Slow operation threshold in milliseconds used by the profiler.")
 	return cmd
 }
 
+// serveMetrics serves the metrics on /metrics until the returned
+// function is called.
+func serveMetrics(addr string, metrics *client.PrometheusMetrics, logger *logrus.Logger) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	srv := &http.Server{Handler: mux}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("metrics server: %v", err)
+		}
+	}()
+	logger.Infof("serving metrics on: http://%v/metrics", ln.Addr())
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}, nil
+}
+
+// isTerminal reports whether the file is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func isReportFormat(format string) bool {
+	for _, f := range client.ReportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// writeReport renders the report to the output file,
+// or to the default output if none is provided.
+// The template, if any, is used instead of the format.
+func writeReport(r *client.Report, format, tmpl, outputFile string) error {
+	write := func(w io.Writer) error {
+		if tmpl != "" {
+			return client.GenerateTemplateReport(w, r, tmpl)
+		}
+		return client.WriteReport(w, r, format)
+	}
+	if outputFile == "" {
+		return write(defaultOutput)
+	}
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func getInterruptCh() <-chan os.Signal {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"mongoperf/internal/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newCommandSchema() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of scenario files.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// GENERATE JSON SCHEMA
+			b, err := json.MarshalIndent(client.ScenarioSchema(), "", "  ")
+			if err != nil {
+				return err
+			}
+			writeOut(string(b))
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mongoperf/internal/client"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// singleRunFlags are the scenario flags which only apply to a single run.
+var singleRunFlags = []string{
+	"report-template", "per-worker", "server-stats", "explain", "profile-level", "profile-slowms",
+	"metrics-addr", "sink", "sink-interval", "live", "trace-file", "trace-format",
+}
+
+// checkSingleRunFlags returns an error when one of the
+// singleRunFlags is set along with the provided mode.
+func checkSingleRunFlags(cmd *cobra.Command, mode string) error {
+	for _, name := range singleRunFlags {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("%v is not supported with %v", name, mode)
+		}
+	}
+	return nil
+}
+
+// sweepConfig holds the scenario command flags used by a sweep.
+type sweepConfig struct {
+	config     []byte
+	set        map[string]string
+	matrix     map[string][]string
+	uri        string
+	isDebug    bool
+	partial    bool
+	format     string
+	outputFile string
+	cooldown   time.Duration
+	reset      bool
+}
+
+// runSweep runs the scenario once per combination of the matrix,
+// then writes the combined report.
+func runSweep(cmd *cobra.Command, cfg sweepConfig) error {
+	// VALIDATE COMMAND LINE ARGS
+	if err := checkSingleRunFlags(cmd, "a sweep"); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("find-max") {
+		return fmt.Errorf("find-max is not supported with a sweep")
+	}
+	if !isSweepFormat(cfg.format) {
+		return fmt.Errorf("output-format must be one of: %v when sweeping", strings.Join(client.SweepFormats, ", "))
+	}
+	if cfg.cooldown < 0 {
+		return fmt.Errorf("cooldown must be greater than or equal to 0")
+	}
+
+	// CREATE LOGGER
+	logger := logrus.New()
+	if cfg.isDebug {
+		logger.SetLevel(logrus.DebugLevel)
+	}
+
+	// BUILD SCENARIOS
+	// every combination is checked before the first run
+	combinations := client.SweepCombinations(cfg.matrix)
+	scenarios := make([]*client.Scenario, len(combinations))
+	for i, combination := range combinations {
+		scenario, err := client.ParseSweepScenario(cfg.config, cfg.set, combination)
+		if err != nil {
+			return fmt.Errorf("%v: %v", formatCombination(combination), err)
+		}
+		if _, err := client.BuildQueriers(scenario); err != nil && !cfg.partial {
+			return fmt.Errorf("%v: %v (use --allow-partial to run the other queries)", formatCombination(combination), err)
+		}
+		scenarios[i] = scenario
+	}
+
+	// SETUP INTERRUPT HANDLER
+	interruptCh := getInterruptCh()
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	go func() {
+		select {
+		case <-interruptCh:
+			cancelCtx()
+		case <-ctx.Done():
+		}
+	}()
+
+	// RUN SCENARIOS
+	var reports []*client.Report
+	for i, scenario := range scenarios {
+		if i > 0 && cfg.cooldown > 0 {
+			logger.Infof("cooling down for %v", cfg.cooldown)
+			select {
+			case <-time.After(cfg.cooldown):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		logger.Infof("running combination %d of %d: %v", i+1, len(scenarios), formatCombination(combinations[i]))
+		report, err := runSweepCombination(ctx, cmd, cfg, logger, scenario, i > 0 && cfg.reset)
+		if err != nil {
+			return fmt.Errorf("%v: %v", formatCombination(combinations[i]), err)
+		}
+		if ctx.Err() != nil {
+			logger.Warnf("%v: interrupted, its results are left out", formatCombination(combinations[i]))
+			break
+		}
+		reports = append(reports, report)
+	}
+
+	// GENERATE REPORT
+	report := client.NewSweepReport(cmd.Parent().Version, cfg.uri, cfg.matrix, reports)
+	if err := writeSweepReport(report, cfg.format, cfg.outputFile); err != nil {
+		return err
+	}
+
+	// EVALUATE THRESHOLDS
+	breached := 0
+	for _, row := range report.Rows {
+		for _, b := range row.Breaches {
+			logger.Errorf("threshold breached: %v: %v: %v", formatCombination(row.Values), b.Query, b.Message)
+		}
+		if len(row.Breaches) > 0 {
+			breached++
+		}
+	}
+	if breached > 0 {
+		cmd.SilenceUsage = true
+		return &exitCodeError{
+			code: exitThresholdBreach,
+			err:  fmt.Errorf("thresholds breached by %d combination(s)", breached),
+		}
+	}
+	return nil
+}
+
+// writeSweepReport renders the sweep report to the output file,
+// or to the default output if none is provided.
+func writeSweepReport(r *client.SweepReport, format, outputFile string) error {
+	if outputFile == "" {
+		return client.WriteSweepReport(defaultOutput, r, format)
+	}
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	if err := client.WriteSweepReport(f, r, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// runSweepCombination connects using the connection pool options
+// of the scenario and runs it, dropping its collection first on reset.
+func runSweepCombination(ctx context.Context, cmd *cobra.Command, cfg sweepConfig, logger *logrus.Logger, scenario *client.Scenario, reset bool) (*client.Report, error) {
+	// START CLIENT
+	logger.Printf("connecting to: %v", cfg.uri)
+	c, err := client.New(context.TODO(), cfg.uri,
+		client.WithLogger(logger),
+		client.WithMaxPoolSize(scenario.MaxPoolSize),
+		client.WithMinPoolSize(scenario.MinPoolSize),
+		client.WithMaxConnIdleTime(scenario.MaxConnIdleTime),
+		client.WithAllowPartial(cfg.partial),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close(context.TODO())
+
+	// RESET COLLECTION
+	if reset {
+		logger.Infof("dropping collection: %v", *scenario.Collection)
+		if err := c.DropCollection(context.TODO(), scenario); err != nil {
+			return nil, err
+		}
+	}
+
+	// RUN SCENARIO
+	results, err := c.RunScenario(ctx, scenario)
+	if err != nil {
+		return nil, err
+	}
+	return client.NewReport(cmd.Parent().Version, cfg.uri, scenario, results), nil
+}
+
+func formatCombination(combination map[string]string) string {
+	values := make([]string, 0, len(combination))
+	for k, v := range combination {
+		values = append(values, k+"="+v)
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
+func isSweepFormat(format string) bool {
+	for _, f := range client.SweepFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"mongoperf/internal/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newCommandValidate() *cobra.Command {
+	var set []string
+	cmd := &cobra.Command{
+		Use:   "validate [scenario-file]",
+		Short: "Check a scenario file without connecting.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// VALIDATE COMMAND LINE ARGS
+			params, err := client.ParseParams(set)
+			if err != nil {
+				return err
+			}
+
+			// VALIDATE SCENARIO
+			cfgFile := args[0]
+			problems, err := client.ValidateScenarioFile(cfgFile, params)
+			if err != nil {
+				return err
+			}
+			for _, p := range problems {
+				writeOut(fmt.Sprintf("%v: %v", cfgFile, p))
+			}
+			if n := len(problems); n > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d problem(s) found", n)
+			}
+			writeOut(fmt.Sprintf("%v: ok", cfgFile))
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVar(&set, "set", nil, "Set a parameter of the scenario, formatted as key=value. Can be repeated.")
+	return cmd
+}
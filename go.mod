@@ -8,4 +8,5 @@ require (
 	github.com/spf13/cobra v0.0.3
 	go.mongodb.org/mongo-driver v1.3.2
 	gopkg.in/yaml.v2 v2.2.4
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"mongoperf/internal/client/query"
+	"strings"
+)
+
+// GenerateActionDoc writes the Meta fields of the action, along with
+// their types, defaults and the supported driver options.
+func GenerateActionDoc(w io.Writer, a query.Action) error {
+	s, err := ActionSchema(a)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v\n", a)
+	writeSchemaFields(&b, s, 1)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeSchemaFields(b *strings.Builder, s *JSONSchema, depth int) {
+	indent := strings.Repeat("  ", depth)
+	required := make(map[string]bool)
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	for _, name := range s.Fields() {
+		fs := s.Properties[name]
+		attrs := []string{schemaTypeName(fs)}
+		if required[name] {
+			attrs = append(attrs, "required")
+		}
+		if fs.Default != nil {
+			attrs = append(attrs, fmt.Sprintf("default: %v", formatDefault(fs.Default)))
+		}
+		fmt.Fprintf(b, "%v%v (%v)\n", indent, name, strings.Join(attrs, ", "))
+		if fs.Description != "" {
+			fmt.Fprintf(b, "%v  %v\n", indent, fs.Description)
+		}
+		if len(fs.Fields()) > 0 {
+			writeSchemaFields(b, fs, depth+1)
+		} else if fs.Items != nil && len(fs.Items.Fields()) > 0 {
+			writeSchemaFields(b, fs.Items, depth+1)
+		}
+	}
+}
+
+// schemaTypeName returns the type of a schema, as written in the README.
+func schemaTypeName(s *JSONSchema) string {
+	switch {
+	case len(s.Enum) > 0:
+		values := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			values = append(values, fmt.Sprint(v))
+		}
+		return "one of " + strings.Join(values, " | ")
+	case s.Type == "object" && s.Title != "":
+		return s.Title
+	case s.Type == "object":
+		return "map"
+	case s.Type == "array":
+		return "List<" + schemaTypeName(s.Items) + ">"
+	case s.duration && s.Type == "integer":
+		return "duration in nanoseconds"
+	case s.duration:
+		return "duration"
+	case s.Type == "integer":
+		return "int"
+	case s.Type == "number":
+		return "float"
+	case s.Type == "boolean":
+		return "bool"
+	case s.Type == "":
+		return "any"
+	}
+	return s.Type
+}
+
+func formatDefault(v interface{}) string {
+	if m, ok := v.(map[string]interface{}); ok && len(m) == 0 {
+		return "{}"
+	}
+	return fmt.Sprint(v)
+}
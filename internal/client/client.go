@@ -2,8 +2,12 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"mongoperf/internal/client/query"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -12,8 +16,13 @@ import (
 
 // Client .
 type Client struct {
-	client *mongo.Client
-	logger *logrus.Logger
+	client        *mongo.Client
+	clientOptions *options.ClientOptions
+	logger        *logrus.Logger
+	pool          *poolMonitor
+	observers     []Observer
+	queue         atomic.Value
+	allowPartial  bool
 }
 
 // Option .
@@ -29,25 +38,76 @@ func WithLogger(l *logrus.Logger) func(c *Client) {
 	return func(c *Client) {}
 }
 
+// WithMaxPoolSize sets the maximum number of connections
+// allowed in the driver connection pool of each server,
+// overriding the value found in the URI.
+func WithMaxPoolSize(n *uint64) func(c *Client) {
+	if n != nil {
+		return func(c *Client) {
+			c.clientOptions.SetMaxPoolSize(*n)
+		}
+	}
+	return func(c *Client) {}
+}
+
+// WithMinPoolSize sets the minimum number of connections
+// maintained in the driver connection pool of each server,
+// overriding the value found in the URI.
+func WithMinPoolSize(n *uint64) func(c *Client) {
+	if n != nil {
+		return func(c *Client) {
+			c.clientOptions.SetMinPoolSize(*n)
+		}
+	}
+	return func(c *Client) {}
+}
+
+// WithMaxConnIdleTime sets the maximum amount of time a connection
+// can remain idle in the pool, overriding the value found in the URI.
+func WithMaxConnIdleTime(d *time.Duration) func(c *Client) {
+	if d != nil {
+		return func(c *Client) {
+			c.clientOptions.SetMaxConnIdleTime(*d)
+		}
+	}
+	return func(c *Client) {}
+}
+
+// WithAllowPartial sets whether scenarios run the queries which
+// could be built when some of them cannot, instead of failing.
+func WithAllowPartial(allow bool) func(c *Client) {
+	return func(c *Client) {
+		c.allowPartial = allow
+	}
+}
+
 // New returns a new Client using the provided URI.
 func New(ctx context.Context, uri string, options ...Option) (*Client, error) {
-	c := &Client{}
-	err := c.connect(ctx, uri)
-	if err != nil {
-		return nil, err
+	c := &Client{
+		clientOptions: newClientOptions(uri),
+		pool:          newPoolMonitor(),
 	}
 	for _, opt := range options {
 		opt(c)
 	}
+	err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
-func (c *Client) connect(ctx context.Context, uri string) error {
-	// Set client options
-	clientOptions := options.Client().ApplyURI(uri)
+func newClientOptions(uri string) *options.ClientOptions {
+	return options.Client().ApplyURI(uri)
+}
+
+func (c *Client) connect(ctx context.Context) error {
+	// Register monitors
+	c.clientOptions.SetPoolMonitor(c.pool.PoolMonitor())
+	c.clientOptions.SetMonitor(c.pool.CommandMonitor())
 
 	// Connect to MongoDB
-	client, err := mongo.Connect(ctx, clientOptions)
+	client, err := mongo.Connect(ctx, c.clientOptions)
 	if err != nil {
 		return err
 	}
@@ -70,25 +130,120 @@ func (c *Client) Close(ctx context.Context) error {
 	return nil
 }
 
+// DropCollection drops the collection of the scenario.
+func (c *Client) DropCollection(ctx context.Context, scenario *Scenario) error {
+	return c.client.Database(*scenario.Database).Collection(*scenario.Collection).Drop(ctx)
+}
+
+// PoolStats returns the connection pool counters
+// recorded since the client was created.
+func (c *Client) PoolStats() PoolStats {
+	return c.pool.Snapshot()
+}
+
+// QueueDepth returns the number of queries waiting
+// for a worker in the scenario currently running.
+func (c *Client) QueueDepth() int {
+	if q, ok := c.queue.Load().(chan task); ok {
+		return len(q)
+	}
+	return 0
+}
+
+// ScenarioResult holds the results of a scenario run
+// aggregated per query and per worker.
+type ScenarioResult struct {
+	Start   time.Time
+	End     time.Time
+	Queries map[string]*ReportAggregator
+	Workers map[int]*ReportAggregator
+}
+
+// QuerierErrors holds the errors of the queries
+// of a scenario which could not be built.
+type QuerierErrors []error
+
+func (e QuerierErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d query(ies) could not be built: %v", len(e), strings.Join(messages, "; "))
+}
+
+// BuildQueriers returns the Querier of every query of the scenario.
+// The queries which could not be built are reported in a QuerierErrors,
+// along with the Queriers of the others.
+func BuildQueriers(scenario *Scenario) ([]query.Querier, error) {
+	var (
+		queriers []query.Querier
+		errs     QuerierErrors
+	)
+	for i := range scenario.Queries {
+		def := &scenario.Queries[i]
+		querier, err := query.NewQuerier(def)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("query %v: %v", *def.Name, err))
+			continue
+		}
+		queriers = append(queriers, querier)
+	}
+	if len(errs) > 0 {
+		return queriers, errs
+	}
+	return queriers, nil
+}
+
+// task is a query to run, sent by the producer to the workers.
+type task struct {
+	querier   query.Querier
+	iteration int
+	scheduled time.Time
+}
+
 // RunScenario .
-func (c *Client) RunScenario(ctx context.Context, scenario *Scenario) (map[string]*ReportAggregator, error) {
+func (c *Client) RunScenario(ctx context.Context, scenario *Scenario) (*ScenarioResult, error) {
 	collection := c.client.Database(*scenario.Database).Collection(*scenario.Collection)
 	c.logger.Infof("using database: %v", *scenario.Database)
 	c.logger.Infof("using collection: %v", *scenario.Collection)
 
+	queriers, err := BuildQueriers(scenario)
+	if err != nil {
+		if !c.allowPartial || len(queriers) == 0 {
+			return nil, err
+		}
+		for _, e := range err.(QuerierErrors) {
+			c.logger.Warnf("skipping %v", e)
+		}
+	}
+	c.logger.Debugf("registered %d of %d queries", len(queriers), len(scenario.Queries))
+
 	bufferSize := *scenario.BufferSize
 	numConsumers := *scenario.Parallel
 	numIteration := *scenario.Repeat
+	var interval time.Duration
+	if r := scenario.Rate; r != nil && *r > 0 {
+		interval = time.Duration(float64(time.Second) / *r)
+	}
 
 	wg := &sync.WaitGroup{}
 	wg.Add(numConsumers)
 
 	closing := make(chan struct{})
 	closed := make(chan struct{})
-	dataCh := make(chan query.Querier, bufferSize)
+	dataCh := make(chan task, bufferSize)
+	c.queue.Store(dataCh)
 	resultCh := make(chan *query.Result, 0)
 
-	results := make(map[string]*ReportAggregator)
+	results := &ScenarioResult{
+		Start:   time.Now(),
+		Queries: make(map[string]*ReportAggregator),
+		Workers: make(map[int]*ReportAggregator),
+	}
+	// register every worker upfront so starved ones are reported
+	for i := 0; i < numConsumers; i++ {
+		results.Workers[i] = NewReportWorker(results.Start)
+	}
 
 	// stop signals the producer to stop sending
 	// it can be called multiple times
@@ -114,23 +269,28 @@ func (c *Client) RunScenario(ctx context.Context, scenario *Scenario) (map[strin
 			close(closed)
 			close(dataCh)
 		}()
-		var queriers []query.Querier
-		for _, def := range scenario.Queries {
-			defCopy := def
-			querier, err := query.NewQuerier(&defCopy)
-			if err != nil {
-				c.logger.Error(err)
-				continue
-			}
-			queriers = append(queriers, querier)
-			c.logger.Debugf("registered query %v with action %v", *defCopy.Name, *defCopy.Action)
-		}
-
 		loops := 0
+		next := time.Now()
 		for {
 			for _, q := range queriers {
+				t := task{querier: q, iteration: loops}
+				// queries are sent on a fixed schedule, which is kept when
+				// the workers fall behind, so that waiting adds to latency
+				if interval > 0 {
+					if wait := time.Until(next); wait > 0 {
+						timer := time.NewTimer(wait)
+						select {
+						case <-timer.C:
+						case <-closing:
+							timer.Stop()
+							return
+						}
+					}
+					t.scheduled = next
+					next = next.Add(interval)
+				}
 				select {
-				case dataCh <- q:
+				case dataCh <- t:
 				case <-closing:
 					return
 				}
@@ -144,33 +304,49 @@ func (c *Client) RunScenario(ctx context.Context, scenario *Scenario) (map[strin
 
 	// N Consumers
 	for i := 0; i < numConsumers; i++ {
-		go func() {
+		go func(id int) {
 			defer wg.Done()
-			for querier := range dataCh {
-				resultCh <- querier.Run(context.TODO(), collection)
+			for _, o := range c.observers {
+				o.WorkerStarted(id)
 			}
-		}()
+			defer func() {
+				for _, o := range c.observers {
+					o.WorkerStopped(id)
+				}
+			}()
+			for t := range dataCh {
+				result := t.querier.Run(withCheckOutTimer(context.TODO()), collection)
+				result.WorkerID = id
+				result.Iteration = t.iteration
+				result.Scheduled = t.scheduled
+				resultCh <- result
+			}
+		}(i)
 	}
 
-	// Add/Update ReportQuery
+	// Add/Update ReportQuery and ReportWorker
 	wgResults := &sync.WaitGroup{}
 	wgResults.Add(1)
-	go func(r map[string]*ReportAggregator) {
+	go func(r *ScenarioResult) {
 		defer wgResults.Done()
 		for result := range resultCh {
-			rq, ok := r[*result.Definition.Name]
+			rq, ok := r.Queries[*result.Definition.Name]
 			if !ok {
-				rq = NewReportQuery(result.Definition, numConsumers)
+				rq = NewReportQuery(result.Definition, numConsumers, r.Start)
+			}
+			rq.Update(result)
+			r.Workers[result.WorkerID].Update(result)
+			r.Queries[*result.Definition.Name] = rq
+			for _, o := range c.observers {
+				o.Observe(result)
 			}
-			delta := result.End.Sub(result.Start)
-			rq.Update(delta, result.TotalChange, result.Error)
-			r[*result.Definition.Name] = rq
 		}
 	}(results)
 
 	wg.Wait()
 	close(resultCh)
 	wgResults.Wait()
+	results.End = time.Now()
 
 	return results, nil
 }
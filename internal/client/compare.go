@@ -0,0 +1,182 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+)
+
+// ReadReportFile returns the Report read from a JSON report file.
+// Sweep and find max reports are rejected.
+func ReadReportFile(fp string) (*Report, error) {
+	filename, err := filepath.Abs(fp)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("%v: %v", fp, err)
+	}
+	// reports written before kinds were added have no kind,
+	// sweep and find max reports have no results
+	if r.Kind != RunReportKind && (r.Kind != "" || r.Results == nil) {
+		kind := r.Kind
+		if kind == "" {
+			kind = "unknown"
+		}
+		return nil, fmt.Errorf("%v: not the report of a single run (kind: %v)", fp, kind)
+	}
+	if r.SchemaVersion != ReportSchemaVersion {
+		return nil, fmt.Errorf("%v: unsupported report schema version %d (expected %d)", fp, r.SchemaVersion, ReportSchemaVersion)
+	}
+	return &r, nil
+}
+
+// MetricDelta is the change of a metric between two reports.
+type MetricDelta struct {
+	Metric     string
+	Baseline   float64
+	Candidate  float64
+	Delta      float64
+	Percent    float64
+	Unit       string
+	Regression bool
+}
+
+// QueryComparison holds the metric deltas of a query found in both reports.
+type QueryComparison struct {
+	Name   string
+	Deltas []MetricDelta
+}
+
+// Comparison is the result of comparing a candidate report to a baseline.
+type Comparison struct {
+	Tolerance     float64
+	Queries       []*QueryComparison
+	BaselineOnly  []string
+	CandidateOnly []string
+}
+
+// Regressions returns the number of metrics which regressed.
+func (c *Comparison) Regressions() int {
+	n := 0
+	for _, q := range c.Queries {
+		for _, d := range q.Deltas {
+			if d.Regression {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// CompareReports matches queries by name and computes the change of their
+// throughput, latency percentiles and error rate. A change is a regression
+// when it worsens the metric by more than tolerance percent.
+func CompareReports(baseline, candidate *Report, tolerance float64) *Comparison {
+	c := &Comparison{Tolerance: tolerance}
+	candidates := make(map[string]*ReportQueryResult)
+	for _, res := range candidate.Results {
+		candidates[res.Name] = res
+	}
+	seen := make(map[string]bool)
+	for _, base := range baseline.Results {
+		cand, ok := candidates[base.Name]
+		if !ok {
+			c.BaselineOnly = append(c.BaselineOnly, base.Name)
+			continue
+		}
+		seen[base.Name] = true
+		c.Queries = append(c.Queries, compareQuery(base, cand, tolerance))
+	}
+	for _, res := range candidate.Results {
+		if !seen[res.Name] {
+			c.CandidateOnly = append(c.CandidateOnly, res.Name)
+		}
+	}
+	sort.Slice(c.Queries, func(i, j int) bool { return c.Queries[i].Name < c.Queries[j].Name })
+	sort.Strings(c.BaselineOnly)
+	sort.Strings(c.CandidateOnly)
+	return c
+}
+
+func compareQuery(base, cand *ReportQueryResult, tolerance float64) *QueryComparison {
+	ms := durationMillis
+	return &QueryComparison{
+		Name: base.Name,
+		Deltas: []MetricDelta{
+			newMetricDelta("eps", "ops/s", base.EPS, cand.EPS, true, tolerance),
+			newMetricDelta("p50", "ms", ms(base.P50), ms(cand.P50), false, tolerance),
+			newMetricDelta("p95", "ms", ms(base.P95), ms(cand.P95), false, tolerance),
+			newMetricDelta("p99", "ms", ms(base.P99), ms(cand.P99), false, tolerance),
+			newMetricDelta("max", "ms", ms(base.Max), ms(cand.Max), false, tolerance),
+			newMetricDelta("errorRate", "%", base.ErrorRate()*100, cand.ErrorRate()*100, false, tolerance),
+		},
+	}
+}
+
+func newMetricDelta(metric, unit string, base, cand float64, higherIsBetter bool, tolerance float64) MetricDelta {
+	d := MetricDelta{
+		Metric:    metric,
+		Unit:      unit,
+		Baseline:  base,
+		Candidate: cand,
+		Delta:     cand - base,
+	}
+	switch {
+	case base != 0:
+		d.Percent = d.Delta / math.Abs(base) * 100
+	case cand != 0:
+		d.Percent = math.Inf(int(math.Copysign(1, cand)))
+	}
+	worsening := d.Percent
+	if higherIsBetter {
+		worsening = -d.Percent
+	}
+	d.Regression = worsening > tolerance
+	return d
+}
+
+// GenerateComparison writes the comparison as a table,
+// flagging regressions.
+func GenerateComparison(w io.Writer, c *Comparison) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "QUERY\tMETRIC\tBASELINE\tCANDIDATE\tDELTA\tDELTA%%\t\n")
+	for _, q := range c.Queries {
+		for _, d := range q.Deltas {
+			flag := ""
+			if d.Regression {
+				flag = "REGRESSION"
+			}
+			fmt.Fprintf(tw, "%v\t%v\t%.3f %v\t%.3f %v\t%+.3f\t%v\t%v\n",
+				q.Name, d.Metric, d.Baseline, d.Unit, d.Candidate, d.Unit, d.Delta, formatPercent(d.Percent), flag)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, name := range c.BaselineOnly {
+		fmt.Fprintf(w, "query %v only found in baseline\n", name)
+	}
+	for _, name := range c.CandidateOnly {
+		fmt.Fprintf(w, "query %v only found in candidate\n", name)
+	}
+	_, err := fmt.Fprintf(w, "\n%d regression(s) above %.1f%% tolerance\n", c.Regressions(), c.Tolerance)
+	return err
+}
+
+func formatPercent(p float64) string {
+	if math.IsInf(p, 0) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", p)
+}
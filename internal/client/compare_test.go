@@ -0,0 +1,107 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewMetricDelta(t *testing.T) {
+	tests := []struct {
+		name           string
+		base, cand     float64
+		higherIsBetter bool
+		percent        float64
+		regression     bool
+	}{
+		{name: "latency within tolerance", base: 10, cand: 11, percent: 10},
+		{name: "latency above tolerance", base: 10, cand: 12, percent: 20, regression: true},
+		{name: "latency improved", base: 10, cand: 5, percent: -50},
+		{name: "throughput within tolerance", base: 100, cand: 90, higherIsBetter: true, percent: -10},
+		{name: "throughput above tolerance", base: 100, cand: 80, higherIsBetter: true, percent: -20, regression: true},
+		{name: "throughput improved", base: 100, cand: 200, higherIsBetter: true, percent: 100},
+		{name: "from zero", base: 0, cand: 1, percent: math.Inf(1), regression: true},
+		{name: "both zero", base: 0, cand: 0, percent: 0},
+		{name: "throughput from zero", base: 0, cand: 1, higherIsBetter: true, percent: math.Inf(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newMetricDelta("m", "u", tt.base, tt.cand, tt.higherIsBetter, 10)
+			if math.Abs(d.Percent-tt.percent) > 1e-9 && !(math.IsInf(d.Percent, 1) && math.IsInf(tt.percent, 1)) {
+				t.Errorf("Percent = %v, want %v", d.Percent, tt.percent)
+			}
+			if d.Regression != tt.regression {
+				t.Errorf("Regression = %v, want %v", d.Regression, tt.regression)
+			}
+		})
+	}
+}
+
+func TestCompareReports(t *testing.T) {
+	baseline := &Report{Results: []*ReportQueryResult{
+		{Name: "find", EPS: 100, P99: 10 * time.Millisecond, QueryCount: 100},
+		{Name: "insert", EPS: 100, QueryCount: 100},
+		{Name: "removed", EPS: 100},
+	}}
+	candidate := &Report{Results: []*ReportQueryResult{
+		{Name: "added", EPS: 100},
+		{Name: "insert", EPS: 100, QueryCount: 100},
+		{Name: "find", EPS: 100, P99: 20 * time.Millisecond, QueryCount: 100},
+	}}
+	c := CompareReports(baseline, candidate, 10)
+	var names []string
+	for _, q := range c.Queries {
+		names = append(names, q.Name)
+	}
+	if want := []string{"find", "insert"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Queries = %v, want %v", names, want)
+	}
+	if !reflect.DeepEqual(c.BaselineOnly, []string{"removed"}) || !reflect.DeepEqual(c.CandidateOnly, []string{"added"}) {
+		t.Errorf("BaselineOnly = %v, CandidateOnly = %v", c.BaselineOnly, c.CandidateOnly)
+	}
+	if n := c.Regressions(); n != 1 {
+		t.Errorf("Regressions() = %d, want 1", n)
+	}
+}
+
+func TestReadReportFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mongoperf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{name: "run", in: fmt.Sprintf(`{"kind": "run", "schemaVersion": %d, "results": []}`, ReportSchemaVersion)},
+		{name: "legacy run", in: fmt.Sprintf(`{"schemaVersion": %d, "results": []}`, ReportSchemaVersion)},
+		{name: "sweep", in: fmt.Sprintf(`{"kind": "sweep", "schemaVersion": %d, "rows": []}`, ReportSchemaVersion), err: "not the report of a single run (kind: sweep)"},
+		{name: "find max", in: fmt.Sprintf(`{"kind": "find-max", "schemaVersion": %d, "steps": []}`, ReportSchemaVersion), err: "not the report of a single run (kind: find-max)"},
+		{name: "legacy find max", in: fmt.Sprintf(`{"schemaVersion": %d, "steps": []}`, ReportSchemaVersion), err: "not the report of a single run (kind: unknown)"},
+		{name: "schema version", in: fmt.Sprintf(`{"kind": "run", "schemaVersion": %d, "results": []}`, ReportSchemaVersion+1), err: "unsupported report schema version"},
+		{name: "invalid", in: `{`, err: "unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := filepath.Join(dir, "report.json")
+			if err := ioutil.WriteFile(fp, []byte(tt.in), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ReadReportFile(fp)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("ReadReportFile() error = %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("ReadReportFile() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
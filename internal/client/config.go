@@ -5,18 +5,29 @@ import (
 	"io/ioutil"
 	"mongoperf/internal/client/query"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
 
 // Scenario .
 type Scenario struct {
-	Database   *string            `yaml:"Database"`
-	Collection *string            `yaml:"Collection"`
-	Parallel   *int               `yaml:"Parallel,omitempty"`
-	BufferSize *int               `yaml:"BufferSize,omitempty"`
-	Repeat     *int               `yaml:"Repeat,omitempty"`
-	Queries    []query.Definition `yaml:"Queries"`
+	Database   *string            `yaml:"Database" json:"Database" required:"true" doc:"Database the queries are sent to."`
+	Collection *string            `yaml:"Collection" json:"Collection" required:"true" doc:"Collection the queries are sent to."`
+	Parallel   *int               `yaml:"Parallel,omitempty" json:"Parallel,omitempty" minimum:"1" default:"1" doc:"Number of workers sending queries concurrently."`
+	BufferSize *int               `yaml:"BufferSize,omitempty" json:"BufferSize,omitempty" minimum:"1" default:"1000" doc:"Number of queries waiting for a worker."`
+	Repeat     *int               `yaml:"Repeat,omitempty" json:"Repeat,omitempty" minimum:"0" default:"1" doc:"Number of times the queries are sent, 0 repeats until interrupted."`
+	Rate       *float64           `yaml:"Rate,omitempty" json:"Rate,omitempty" minimum:"0" doc:"Number of queries sent per second, 0 sends them as fast as the workers process them."`
+	Queries    []query.Definition `yaml:"Queries" json:"Queries" required:"true" minItems:"1" doc:"Queries sent in order to the workers."`
+
+	MaxPoolSize     *uint64        `yaml:"MaxPoolSize,omitempty" json:"MaxPoolSize,omitempty" doc:"Maximum number of connections of the pool of each server."`
+	MinPoolSize     *uint64        `yaml:"MinPoolSize,omitempty" json:"MinPoolSize,omitempty" doc:"Minimum number of connections of the pool of each server."`
+	MaxConnIdleTime *time.Duration `yaml:"MaxConnIdleTime,omitempty" json:"MaxConnIdleTime,omitempty" doc:"Maximum time a connection can remain idle in the pool."`
+
+	Thresholds *query.Thresholds `yaml:"Thresholds,omitempty" json:"Thresholds,omitempty" doc:"Limits the results of the whole run must respect."`
+
+	Params map[string]string   `yaml:"Params,omitempty" json:"Params,omitempty" doc:"Default values of the parameters referenced using ${name}."`
+	Matrix map[string][]string `yaml:"Matrix,omitempty" json:"Matrix,omitempty" doc:"Values of the attributes and parameters to sweep, running the scenario once per combination."`
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaller interface.
@@ -53,15 +64,30 @@ func (c *Scenario) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return fmt.Errorf("Repeat must be greater than or equal to 0")
 	default:
 	}
+	if r := c.Rate; r != nil && *r < 0 {
+		return fmt.Errorf("Rate must be greater than or equal to 0")
+	}
 	if len(c.Queries) == 0 {
 		return fmt.Errorf("Queries must not be empty")
 	}
+	if c.MaxPoolSize != nil && c.MinPoolSize != nil && *c.MaxPoolSize > 0 && *c.MinPoolSize > *c.MaxPoolSize {
+		return fmt.Errorf("MinPoolSize must be less than or equal to MaxPoolSize")
+	}
+	if d := c.MaxConnIdleTime; d != nil && *d < 0 {
+		return fmt.Errorf("MaxConnIdleTime must be greater than or equal to 0")
+	}
+	for k, values := range c.Matrix {
+		if len(values) == 0 {
+			return fmt.Errorf("Matrix.%v must not be empty", k)
+		}
+	}
 	return nil
 }
 
 // ParseScenarioFile returns a Config from parsing the file
-// using the provided filepath.
-func ParseScenarioFile(fp string) (*Scenario, error) {
+// using the provided filepath. The parameters override
+// the Params of the scenario.
+func ParseScenarioFile(fp string, set map[string]string) (*Scenario, error) {
 	filename, err := filepath.Abs(fp)
 	if err != nil {
 		return nil, err
@@ -70,11 +96,27 @@ func ParseScenarioFile(fp string) (*Scenario, error) {
 	if err != nil {
 		return nil, err
 	}
-	return ParseScenario(f)
+	return ParseScenario(f, set)
+}
+
+// ParseScenario returns a Config from bytes, after interpolating
+// its variables. The parameters override the Params of the scenario.
+func ParseScenario(b []byte, set map[string]string) (*Scenario, error) {
+	out, params, err := InterpolateScenario(b, set)
+	if err != nil {
+		return nil, err
+	}
+	c, err := parseScenario(out)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) > 0 {
+		c.Params = params
+	}
+	return c, nil
 }
 
-// ParseScenario returns a Config from bytes.
-func ParseScenario(b []byte) (*Scenario, error) {
+func parseScenario(b []byte) (*Scenario, error) {
 	var c Scenario
 	if err := yaml.Unmarshal(b, &c); err != nil {
 		return nil, err
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"net"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Error classes .
+const (
+	ErrorClassTimeout      = "timeout"
+	ErrorClassCanceled     = "canceled"
+	ErrorClassNetwork      = "network"
+	ErrorClassDuplicateKey = "duplicate_key"
+	ErrorClassWriteConcern = "write_concern"
+	ErrorClassWrite        = "write"
+	ErrorClassCommand      = "command"
+	ErrorClassOther        = "other"
+)
+
+// ErrorClass returns a coarse classification of a query error,
+// suitable as a metric label.
+func ErrorClass(err error) string {
+	switch e := err.(type) {
+	case mongo.CommandError:
+		switch {
+		case e.IsMaxTimeMSExpiredError():
+			return ErrorClassTimeout
+		case e.HasErrorLabel("NetworkError"):
+			return ErrorClassNetwork
+		case isDuplicateKeyCode(int(e.Code)):
+			return ErrorClassDuplicateKey
+		}
+		return ErrorClassCommand
+	case mongo.WriteException:
+		for _, we := range e.WriteErrors {
+			if isDuplicateKeyCode(we.Code) {
+				return ErrorClassDuplicateKey
+			}
+		}
+		if e.WriteConcernError != nil {
+			return ErrorClassWriteConcern
+		}
+		return ErrorClassWrite
+	case mongo.BulkWriteException:
+		for _, we := range e.WriteErrors {
+			if isDuplicateKeyCode(we.Code) {
+				return ErrorClassDuplicateKey
+			}
+		}
+		if e.WriteConcernError != nil {
+			return ErrorClassWriteConcern
+		}
+		return ErrorClassWrite
+	case net.Error:
+		if e.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+	switch err {
+	case context.DeadlineExceeded:
+		return ErrorClassTimeout
+	case context.Canceled:
+		return ErrorClassCanceled
+	}
+	return ErrorClassOther
+}
+
+func isDuplicateKeyCode(code int) bool {
+	return code == 11000 || code == 11001 || code == 12582
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"mongoperf/internal/client/query"
+)
+
+// explainExaminedRatio is the docsExamined/nReturned ratio
+// above which a query plan is reported as inefficient.
+const explainExaminedRatio = 10
+
+// ExplainScenario runs explain once for every read query of the scenario
+// which enabled Explain, or for all of them if all is true. Queries whose
+// action does not support explain, only Find and FindOneAction do, are
+// skipped with a warning.
+func (c *Client) ExplainScenario(ctx context.Context, scenario *Scenario, all bool) ([]*query.ExplainResult, error) {
+	collection := c.client.Database(*scenario.Database).Collection(*scenario.Collection)
+
+	var results []*query.ExplainResult
+	for _, def := range scenario.Queries {
+		defCopy := def
+		if !all && (defCopy.Explain == nil || !*defCopy.Explain) {
+			continue
+		}
+		querier, err := query.NewQuerier(&defCopy)
+		if err != nil {
+			return nil, err
+		}
+		explainer, ok := querier.(query.Explainer)
+		if !ok {
+			c.logger.Warnf("query %v: explain skipped, action %v does not support it (only %v and %v do)",
+				*defCopy.Name, *defCopy.Action, query.FindAction, query.FindOneAction)
+			continue
+		}
+		result, err := explainer.Explain(ctx, collection)
+		if err != nil {
+			return nil, fmt.Errorf("explain %v: %v", *defCopy.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ReportExplain .
+type ReportExplain struct {
+	Name                string `json:"name"`
+	Action              string `json:"action"`
+	Stage               string `json:"stage"`
+	IndexName           string `json:"indexName"`
+	KeysExamined        int64  `json:"keysExamined"`
+	DocsExamined        int64  `json:"docsExamined"`
+	NReturned           int64  `json:"nReturned"`
+	ExecutionTimeMillis int64  `json:"executionTimeMillis"`
+	Warning             string `json:"warning"`
+}
+
+// NewReportExplains .
+func NewReportExplains(results []*query.ExplainResult) []*ReportExplain {
+	var explains []*ReportExplain
+	for _, res := range results {
+		indexName := res.IndexName
+		if indexName == "" {
+			indexName = "none"
+		}
+		explains = append(explains, &ReportExplain{
+			Name:                *res.Definition.Name,
+			Action:              string(*res.Definition.Action),
+			Stage:               res.Stage(),
+			IndexName:           indexName,
+			KeysExamined:        res.KeysExamined,
+			DocsExamined:        res.DocsExamined,
+			NReturned:           res.NReturned,
+			ExecutionTimeMillis: res.ExecutionTimeMillis,
+			Warning:             explainWarning(res),
+		})
+	}
+	return explains
+}
+
+func explainWarning(res *query.ExplainResult) string {
+	returned := res.NReturned
+	if returned == 0 {
+		returned = 1
+	}
+	ratio := res.DocsExamined / returned
+	switch {
+	case ratio > explainExaminedRatio && res.IsCollScan():
+		return fmt.Sprintf("COLLSCAN examined %d documents per document returned", ratio)
+	case ratio > explainExaminedRatio:
+		return fmt.Sprintf("examined %d documents per document returned", ratio)
+	case res.IsCollScan():
+		return "COLLSCAN"
+	}
+	return "nil"
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"mongoperf/internal/client/query"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestExplainWarning(t *testing.T) {
+	tests := []struct {
+		name string
+		res  query.ExplainResult
+		want string
+	}{
+		{
+			name: "index scan",
+			res:  query.ExplainResult{Stages: []string{"FETCH", "IXSCAN"}, DocsExamined: 10, NReturned: 10},
+			want: "nil",
+		},
+		{
+			name: "collection scan",
+			res:  query.ExplainResult{Stages: []string{"COLLSCAN"}, DocsExamined: 10, NReturned: 5},
+			want: "COLLSCAN",
+		},
+		{
+			name: "inefficient collection scan",
+			res:  query.ExplainResult{Stages: []string{"LIMIT", "COLLSCAN"}, DocsExamined: 1000, NReturned: 2},
+			want: "COLLSCAN examined 500 documents per document returned",
+		},
+		{
+			name: "inefficient index scan",
+			res:  query.ExplainResult{Stages: []string{"FETCH", "IXSCAN"}, DocsExamined: 110, NReturned: 10},
+			want: "examined 11 documents per document returned",
+		},
+		{
+			name: "nothing returned",
+			res:  query.ExplainResult{Stages: []string{"FETCH", "IXSCAN"}, DocsExamined: 11},
+			want: "examined 11 documents per document returned",
+		},
+		{
+			name: "ratio at the limit",
+			res:  query.ExplainResult{Stages: []string{"IXSCAN"}, DocsExamined: 10},
+			want: "nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := explainWarning(&tt.res); got != tt.want {
+				t.Errorf("explainWarning() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainScenarioSkipped(t *testing.T) {
+	// skipped queries are not sent, so the client is never connected
+	mc, err := mongo.NewClient(options.Client().ApplyURI("mongodb://localhost:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &logs
+	c := &Client{client: mc, logger: logger}
+
+	definition := func(name string, action query.Action, explain bool, meta map[string]interface{}) query.Definition {
+		return query.Definition{Name: &name, Action: &action, Explain: &explain, Meta: meta}
+	}
+	database, collection := "db", "c"
+	scenario := &Scenario{Database: &database, Collection: &collection, Queries: []query.Definition{
+		definition("insert", query.InsertOneAction, true, map[string]interface{}{"Data": map[string]interface{}{"a": 1}}),
+		definition("delete", query.DeleteOneAction, false, map[string]interface{}{"Filter": map[string]interface{}{"a": 1}}),
+	}}
+
+	tests := []struct {
+		all  bool
+		want []string
+	}{
+		{all: false, want: []string{"query insert: explain skipped, action InsertOne does not support it (only Find and FindOneAction do)"}},
+		{all: true, want: []string{"query insert: explain skipped", "query delete: explain skipped, action DeleteOne"}},
+	}
+	for _, tt := range tests {
+		logs.Reset()
+		results, err := c.ExplainScenario(context.Background(), scenario, tt.all)
+		if err != nil || len(results) != 0 {
+			t.Fatalf("all %v: got %d results and error %v", tt.all, len(results), err)
+		}
+		out := logs.String()
+		if got := strings.Count(out, "level=warning"); got != len(tt.want) {
+			t.Errorf("all %v: got %d warnings, want %d: %q", tt.all, got, len(tt.want), out)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("all %v: warnings %q do not contain %q", tt.all, out, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// FindMaxMinAchieved is the fraction of the target rate a step must
+// achieve to hold, the client falling behind otherwise.
+const FindMaxMinAchieved = 0.9
+
+// FindMax phases .
+const (
+	FindMaxRamp   = "ramp"
+	FindMaxSearch = "search"
+)
+
+// FindMaxOptions configures the search of the maximum sustainable rate.
+type FindMaxOptions struct {
+	// P99Target is the latency SLO the steps must hold.
+	P99Target time.Duration `json:"p99Target"`
+	// MaxErrorRate is the fraction of failed queries allowed.
+	MaxErrorRate float64 `json:"maxErrorRate"`
+	// StartRate is the rate of the first step, in queries per second.
+	StartRate float64 `json:"startRate"`
+	// StepDuration is how long every step runs.
+	StepDuration time.Duration `json:"stepDuration"`
+	// Precision is the percentage by which the rates which held and broke
+	// the SLO can differ when the search stops.
+	Precision float64 `json:"precision"`
+	// MaxSteps is the maximum number of steps.
+	MaxSteps int `json:"maxSteps"`
+	// Cooldown is the time to wait between steps.
+	Cooldown time.Duration `json:"cooldown"`
+}
+
+// FindMaxStep holds the results of the whole run of a step.
+type FindMaxStep struct {
+	Phase   string             `json:"phase"`
+	Rate    float64            `json:"rate"`
+	Elapsed time.Duration      `json:"elapsed"`
+	Total   *ReportQueryResult `json:"total"`
+	Held    bool               `json:"held"`
+	// Reasons lists why the step broke the SLO.
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// FindMaxReport is the result of the search of the maximum sustainable rate.
+type FindMaxReport struct {
+	Kind          string         `json:"kind"`
+	SchemaVersion int            `json:"schemaVersion"`
+	GeneratedAt   time.Time      `json:"generatedAt"`
+	Version       string         `json:"version"`
+	URI           string         `json:"uri"`
+	Options       FindMaxOptions `json:"options"`
+
+	// MaxRate is the highest rate which held the SLO, 0 when none did.
+	MaxRate float64        `json:"maxRate"`
+	Steps   []*FindMaxStep `json:"steps"`
+}
+
+// FindMaxRate searches the highest rate at which the scenario holds the
+// SLO. Starting from StartRate, the rate is doubled until a step breaks
+// the SLO, then the boundary is searched by bisection, until the rates
+// which held and broke it are within Precision percent. Every step
+// repeats the queries of the scenario for StepDuration.
+//
+// When interrupted, the steps which completed are returned.
+func (c *Client) FindMaxRate(ctx context.Context, scenario *Scenario, opts FindMaxOptions) (*FindMaxReport, error) {
+	return c.findMaxRate(ctx, opts, func(ctx context.Context, rate float64) (*FindMaxStep, error) {
+		return c.runFindMaxStep(ctx, scenario, opts, rate)
+	})
+}
+
+// findMaxRate searches the maximum rate using runStep to run every step.
+func (c *Client) findMaxRate(ctx context.Context, opts FindMaxOptions, runStep func(context.Context, float64) (*FindMaxStep, error)) (*FindMaxReport, error) {
+	r := &FindMaxReport{
+		Kind:          FindMaxReportKind,
+		SchemaVersion: ReportSchemaVersion,
+		GeneratedAt:   time.Now(),
+		Options:       opts,
+	}
+	var (
+		held  float64
+		broke float64
+		rate  = opts.StartRate
+		phase = FindMaxRamp
+	)
+	for len(r.Steps) < opts.MaxSteps {
+		if len(r.Steps) > 0 && opts.Cooldown > 0 {
+			c.logger.Infof("cooling down for %v", opts.Cooldown)
+			select {
+			case <-time.After(opts.Cooldown):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		c.logger.Infof("step %d: %v at %.2f queries/s", len(r.Steps)+1, phase, rate)
+		step, err := runStep(ctx, rate)
+		if err != nil {
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			c.logger.Warnf("step %d: interrupted, its results are left out", len(r.Steps)+1)
+			break
+		}
+		step.Phase = phase
+		r.Steps = append(r.Steps, step)
+		if step.Held {
+			held = rate
+			r.MaxRate = rate
+		} else {
+			broke = rate
+			c.logger.Infof("step %d: SLO broken: %v", len(r.Steps), strings.Join(step.Reasons, ", "))
+		}
+
+		// NEXT RATE
+		if broke == 0 {
+			rate *= 2
+			continue
+		}
+		if held > 0 && (broke-held)/held*100 <= opts.Precision {
+			break
+		}
+		phase = FindMaxSearch
+		rate = (held + broke) / 2
+	}
+	return r, nil
+}
+
+func (c *Client) runFindMaxStep(ctx context.Context, scenario *Scenario, opts FindMaxOptions, rate float64) (*FindMaxStep, error) {
+	// the queries are repeated until the end of the step
+	s := *scenario
+	s.Rate = &rate
+	s.Repeat = Int(0)
+	stepCtx, cancel := context.WithTimeout(ctx, opts.StepDuration)
+	defer cancel()
+	results, err := c.RunScenario(stepCtx, &s)
+	if err != nil {
+		return nil, err
+	}
+	report := NewReport("", "", &s, results)
+	step := &FindMaxStep{
+		Rate:    rate,
+		Elapsed: report.Elapsed,
+		Total:   report.Total,
+	}
+	if p99 := step.Total.P99; p99 > opts.P99Target {
+		step.Reasons = append(step.Reasons, fmt.Sprintf("p99 latency %v exceeds %v", p99, opts.P99Target))
+	}
+	if errorRate := step.Total.ErrorRate(); errorRate > opts.MaxErrorRate {
+		step.Reasons = append(step.Reasons, fmt.Sprintf("error rate %.2f%% exceeds %.2f%%", errorRate*100, opts.MaxErrorRate*100))
+	}
+	if eps := step.Total.EPS; eps < rate*FindMaxMinAchieved {
+		step.Reasons = append(step.Reasons, fmt.Sprintf("achieved %.2f queries/s, below %.0f%% of the rate", eps, FindMaxMinAchieved*100))
+	}
+	step.Held = len(step.Reasons) == 0
+	return step, nil
+}
+
+// FindMaxFormats lists the supported find max report formats.
+var FindMaxFormats = []string{TextFormat, JSONFormat}
+
+// WriteFindMaxReport renders the find max report using the provided format.
+func WriteFindMaxReport(w io.Writer, r *FindMaxReport, format string) error {
+	switch format {
+	case TextFormat:
+		return GenerateFindMaxReport(w, r)
+	case JSONFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return fmt.Errorf("find max report format not supported: %v", format)
+}
+
+// GenerateFindMaxReport writes a table row per step,
+// followed by the highest rate which held the SLO.
+func GenerateFindMaxReport(w io.Writer, r *FindMaxReport) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "STEP\tPHASE\tRATE\tEPS\tQUERYCOUNT\tERRORRATE\tP50\tP95\tP99\tMAX\tSLO\t\n")
+	for i, s := range r.Steps {
+		slo := "held"
+		if !s.Held {
+			slo = "broken: " + strings.Join(s.Reasons, ", ")
+		}
+		fmt.Fprintf(tw, "%d\t%v\t%.2f\t%.2f\t%d\t%.2f%%\t%v\t%v\t%v\t%v\t%v\n",
+			i+1, s.Phase, s.Rate, s.Total.EPS, s.Total.QueryCount, s.Total.ErrorRate()*100,
+			s.Total.P50, s.Total.P95, s.Total.P99, s.Total.Max, slo)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	slo := fmt.Sprintf("p99 <= %v, error rate <= %.2f%%", r.Options.P99Target, r.Options.MaxErrorRate*100)
+	if r.MaxRate == 0 {
+		_, err := fmt.Fprintf(w, "\nno rate held the SLO (%v)\n", slo)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nmax sustainable rate: %.2f queries/s (%v)\n", r.MaxRate, slo)
+	return err
+}
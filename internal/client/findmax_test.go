@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestFindMaxRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   float64
+		opts    FindMaxOptions
+		rates   []float64
+		maxRate float64
+	}{
+		{
+			name:    "ramp then bisect",
+			limit:   700,
+			opts:    FindMaxOptions{StartRate: 100, Precision: 10, MaxSteps: 20},
+			rates:   []float64{100, 200, 400, 800, 600, 700, 750},
+			maxRate: 700,
+		},
+		{
+			name:    "first step breaks",
+			limit:   50,
+			opts:    FindMaxOptions{StartRate: 100, Precision: 10, MaxSteps: 20},
+			rates:   []float64{100, 50, 75, 62.5, 56.25, 53.125},
+			maxRate: 50,
+		},
+		{
+			name:    "max steps",
+			limit:   math.Inf(1),
+			opts:    FindMaxOptions{StartRate: 100, Precision: 10, MaxSteps: 3},
+			rates:   []float64{100, 200, 400},
+			maxRate: 400,
+		},
+		{
+			name:    "nothing holds",
+			limit:   0,
+			opts:    FindMaxOptions{StartRate: 100, Precision: 10, MaxSteps: 4},
+			rates:   []float64{100, 50, 25, 12.5},
+			maxRate: 0,
+		},
+	}
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	c := &Client{logger: logger}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runStep := func(ctx context.Context, rate float64) (*FindMaxStep, error) {
+				step := &FindMaxStep{Rate: rate, Held: rate <= tt.limit}
+				if !step.Held {
+					step.Reasons = []string{"too fast"}
+				}
+				return step, nil
+			}
+			r, err := c.findMaxRate(context.Background(), tt.opts, runStep)
+			if err != nil {
+				t.Fatalf("findMaxRate() error = %v", err)
+			}
+			var rates []float64
+			for _, s := range r.Steps {
+				rates = append(rates, s.Rate)
+			}
+			if !reflect.DeepEqual(rates, tt.rates) || r.MaxRate != tt.maxRate {
+				t.Errorf("findMaxRate() ran %v and found %v, want %v and %v", rates, r.MaxRate, tt.rates, tt.maxRate)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"math"
+	"time"
+)
+
+// Histogram bucket boundaries grow exponentially from histogramMin,
+// giving percentiles with a relative error of at most 5%.
+const (
+	histogramMin     = time.Microsecond
+	histogramGrowth  = 1.05
+	histogramBuckets = 512
+)
+
+var histogramLogGrowth = math.Log(histogramGrowth)
+
+// Histogram records durations in a fixed amount of memory.
+type Histogram struct {
+	Counts []int64
+	Count  int64
+	Sum    time.Duration
+	Min    time.Duration
+	Max    time.Duration
+}
+
+// NewHistogram .
+func NewHistogram() *Histogram {
+	return &Histogram{Counts: make([]int64, histogramBuckets)}
+}
+
+// Record adds a duration to the histogram.
+func (h *Histogram) Record(d time.Duration) {
+	h.Counts[histogramBucket(d)]++
+	if h.Count == 0 || d < h.Min {
+		h.Min = d
+	}
+	if d > h.Max {
+		h.Max = d
+	}
+	h.Count++
+	h.Sum += d
+}
+
+// Merge adds the durations recorded by o to the histogram.
+func (h *Histogram) Merge(o *Histogram) {
+	if o.Count == 0 {
+		return
+	}
+	for i, n := range o.Counts {
+		h.Counts[i] += n
+	}
+	if h.Count == 0 || o.Min < h.Min {
+		h.Min = o.Min
+	}
+	if o.Max > h.Max {
+		h.Max = o.Max
+	}
+	h.Count += o.Count
+	h.Sum += o.Sum
+}
+
+// Mean returns the average recorded duration.
+func (h *Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return time.Duration(int64(h.Sum) / h.Count)
+}
+
+// Percentile returns the duration below which p percent
+// of the recorded durations fall.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(p / 100 * float64(h.Count)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i, n := range h.Counts {
+		seen += n
+		if seen >= rank {
+			// the last bucket also holds the longer durations
+			d := HistogramBucketBound(i)
+			if d > h.Max || i == histogramBuckets-1 {
+				d = h.Max
+			}
+			if d < h.Min {
+				d = h.Min
+			}
+			return d
+		}
+	}
+	return h.Max
+}
+
+// HistogramBucket is a non empty bucket of a Histogram.
+type HistogramBucket struct {
+	UpperBound time.Duration `json:"upperBound"`
+	Count      int64         `json:"count"`
+}
+
+// Buckets returns the non empty buckets of the histogram.
+func (h *Histogram) Buckets() []HistogramBucket {
+	var buckets []HistogramBucket
+	for i, n := range h.Counts {
+		if n > 0 {
+			buckets = append(buckets, HistogramBucket{UpperBound: HistogramBucketBound(i), Count: n})
+		}
+	}
+	return buckets
+}
+
+// HistogramBucketBound returns the upper bound of bucket i.
+func HistogramBucketBound(i int) time.Duration {
+	return time.Duration(float64(histogramMin) * math.Pow(histogramGrowth, float64(i)))
+}
+
+func histogramBucket(d time.Duration) int {
+	if d <= histogramMin {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(float64(d)/float64(histogramMin)) / histogramLogGrowth))
+	if i >= histogramBuckets {
+		return histogramBuckets - 1
+	}
+	return i
+}
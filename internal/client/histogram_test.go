@@ -0,0 +1,64 @@
+package client
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogramPercentile(t *testing.T) {
+	h := NewHistogram()
+	for i := 1; i <= 1000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{p: 0, want: time.Millisecond},
+		{p: 50, want: 500 * time.Millisecond},
+		{p: 95, want: 950 * time.Millisecond},
+		{p: 99, want: 990 * time.Millisecond},
+		{p: 99.9, want: 999 * time.Millisecond},
+		{p: 100, want: 1000 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		got := h.Percentile(tt.p)
+		if e := math.Abs(float64(got-tt.want)) / float64(tt.want); e > histogramGrowth-1 {
+			t.Errorf("Percentile(%v) = %v, want %v within %.0f%%", tt.p, got, tt.want, (histogramGrowth-1)*100)
+		}
+		if got < h.Min || got > h.Max {
+			t.Errorf("Percentile(%v) = %v, want it between %v and %v", tt.p, got, h.Min, h.Max)
+		}
+	}
+}
+
+func TestHistogramEdges(t *testing.T) {
+	h := NewHistogram()
+	if got := h.Percentile(99); got != 0 {
+		t.Errorf("Percentile() of an empty histogram = %v, want 0", got)
+	}
+	h.Record(0)
+	h.Record(time.Hour * 24 * 365)
+	if got := h.Percentile(100); got != h.Max {
+		t.Errorf("Percentile(100) = %v, want the max %v", got, h.Max)
+	}
+	if got := h.Percentile(1); got > histogramMin {
+		t.Errorf("Percentile(1) = %v, want the upper bound of the first bucket", got)
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	a, b := NewHistogram(), NewHistogram()
+	a.Record(2 * time.Millisecond)
+	b.Record(time.Millisecond)
+	b.Record(3 * time.Millisecond)
+	a.Merge(b)
+	a.Merge(NewHistogram())
+	if a.Count != 3 || a.Min != time.Millisecond || a.Max != 3*time.Millisecond || a.Mean() != 2*time.Millisecond {
+		t.Errorf("Merge() = count %d, min %v, max %v, mean %v", a.Count, a.Min, a.Max, a.Mean())
+	}
+	if n := len(a.Buckets()); n != 3 {
+		t.Errorf("Buckets() returned %d buckets, want 3", n)
+	}
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"mongoperf/internal/client/query"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LiveInterval is the refresh interval of the LiveMonitor.
+const LiveInterval = time.Second
+
+// liveLatencyPrecision is the precision of the latencies shown.
+const liveLatencyPrecision = 10 * time.Microsecond
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+type liveQuery struct {
+	ops     int64
+	errors  int64
+	latency *Histogram
+}
+
+// LiveQueryStats holds the progress of a query during a run.
+type LiveQueryStats struct {
+	Name      string
+	OpsPerSec float64
+	Ops       int64
+	Errors    int64
+	P50       time.Duration
+	P95       time.Duration
+	P99       time.Duration
+}
+
+// LiveSnapshot holds the progress of a run.
+type LiveSnapshot struct {
+	Elapsed    time.Duration
+	Workers    int
+	QueueDepth int
+	Queries    []LiveQueryStats
+}
+
+// LiveMonitor is an Observer showing the progress of a run
+// every LiveInterval.
+//
+// When the output is a terminal, the screen is redrawn with
+// a table of the queries. Otherwise, a line per query is logged.
+type LiveMonitor struct {
+	w          io.Writer
+	tty        bool
+	logger     *logrus.Logger
+	queueDepth func() int
+
+	mu            sync.Mutex
+	start         time.Time
+	last          time.Time
+	activeWorkers int
+	queries       map[string]*liveQuery
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewLiveMonitor returns a LiveMonitor drawing to w when tty is true,
+// and logging using the logger otherwise.
+func NewLiveMonitor(w io.Writer, tty bool, logger *logrus.Logger) *LiveMonitor {
+	return &LiveMonitor{
+		w:       w,
+		tty:     tty,
+		logger:  logger,
+		queries: make(map[string]*liveQuery),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+}
+
+// WorkerStarted implements the Observer interface.
+func (m *LiveMonitor) WorkerStarted(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeWorkers++
+}
+
+// WorkerStopped implements the Observer interface.
+func (m *LiveMonitor) WorkerStopped(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeWorkers--
+}
+
+// Observe implements the Observer interface.
+func (m *LiveMonitor) Observe(result *query.Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name := *result.Definition.Name
+	q, ok := m.queries[name]
+	if !ok {
+		q = &liveQuery{latency: NewHistogram()}
+		m.queries[name] = q
+	}
+	q.ops++
+	q.latency.Record(result.Latency())
+	if result.Error != nil {
+		q.errors++
+	}
+}
+
+// Start shows the progress every LiveInterval until Stop is called.
+// The queueDepth function returns the number of queries waiting
+// for a worker.
+func (m *LiveMonitor) Start(queueDepth func() int) {
+	m.mu.Lock()
+	m.queueDepth = queueDepth
+	m.start = time.Now()
+	m.last = m.start
+	m.mu.Unlock()
+	go func() {
+		defer close(m.doneCh)
+		ticker := time.NewTicker(LiveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case t := <-ticker.C:
+				m.show(m.snapshot(t))
+			case <-m.stopCh:
+				m.show(m.snapshot(time.Now()))
+				return
+			}
+		}
+	}()
+}
+
+// Stop shows the progress a last time.
+func (m *LiveMonitor) Stop() {
+	close(m.stopCh)
+	<-m.doneCh
+}
+
+// snapshot returns the progress of the run. Throughput is
+// computed over the time elapsed since the previous snapshot,
+// latency percentiles over the whole run.
+func (m *LiveMonitor) snapshot(t time.Time) *LiveSnapshot {
+	depth := m.queueDepth()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	interval := t.Sub(m.last).Seconds()
+	m.last = t
+
+	snapshot := &LiveSnapshot{
+		Elapsed:    t.Sub(m.start),
+		Workers:    m.activeWorkers,
+		QueueDepth: depth,
+	}
+	for name, q := range m.queries {
+		stats := LiveQueryStats{
+			Name:   name,
+			Ops:    q.latency.Count,
+			Errors: q.errors,
+			P50:    q.latency.Percentile(50).Round(liveLatencyPrecision),
+			P95:    q.latency.Percentile(95).Round(liveLatencyPrecision),
+			P99:    q.latency.Percentile(99).Round(liveLatencyPrecision),
+		}
+		if interval > 0 {
+			stats.OpsPerSec = float64(q.ops) / interval
+		}
+		q.ops = 0
+		snapshot.Queries = append(snapshot.Queries, stats)
+	}
+	sort.Slice(snapshot.Queries, func(i, j int) bool { return snapshot.Queries[i].Name < snapshot.Queries[j].Name })
+	return snapshot
+}
+
+func (m *LiveMonitor) show(s *LiveSnapshot) {
+	elapsed := s.Elapsed.Round(time.Second)
+	if !m.tty {
+		m.logger.Infof("elapsed: %v, workers: %d, queue depth: %d", elapsed, s.Workers, s.QueueDepth)
+		for _, q := range s.Queries {
+			m.logger.Infof("%v: %.1f ops/sec, total: %d, p50: %v, p95: %v, p99: %v, errors: %d",
+				q.Name, q.OpsPerSec, q.Ops, q.P50, q.P95, q.P99, q.Errors)
+		}
+		return
+	}
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	fmt.Fprintf(&b, "Elapsed: %v    Workers: %d    Queue depth: %d\n\n", elapsed, s.Workers, s.QueueDepth)
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "QUERY\tOPS/SEC\tTOTAL\tP50\tP95\tP99\tERRORS\t\n")
+	for _, q := range s.Queries {
+		fmt.Fprintf(tw, "%v\t%.1f\t%d\t%v\t%v\t%v\t%d\t\n", q.Name, q.OpsPerSec, q.Ops, q.P50, q.P95, q.P99, q.Errors)
+	}
+	tw.Flush()
+	io.WriteString(m.w, b.String())
+}
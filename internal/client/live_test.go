@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"mongoperf/internal/client/query"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestLiveMonitor(w *bytes.Buffer, tty bool) (*LiveMonitor, time.Time) {
+	logger := logrus.New()
+	logger.Out = w
+	m := NewLiveMonitor(w, tty, logger)
+	m.queueDepth = func() int { return 7 }
+	m.start = time.Now()
+	m.last = m.start
+
+	for id := 0; id < 3; id++ {
+		m.WorkerStarted(id)
+	}
+	m.WorkerStopped(0)
+	start := m.start
+	for i, name := range []string{"b", "b", "a", "b"} {
+		name := name
+		r := &query.Result{Definition: &query.Definition{Name: &name}, Start: start, End: start.Add(time.Duration(i+1) * time.Millisecond)}
+		if name == "a" {
+			r.Error = errors.New("failed")
+		}
+		m.Observe(r)
+	}
+	return m, start
+}
+
+func TestLiveMonitorSnapshot(t *testing.T) {
+	m, start := newTestLiveMonitor(&bytes.Buffer{}, false)
+
+	s := m.snapshot(start.Add(2 * time.Second))
+	if s.Elapsed != 2*time.Second || s.Workers != 2 || s.QueueDepth != 7 {
+		t.Errorf("got elapsed %v, %d workers and queue depth %d", s.Elapsed, s.Workers, s.QueueDepth)
+	}
+	want := []LiveQueryStats{
+		{Name: "a", OpsPerSec: 0.5, Ops: 1, Errors: 1},
+		{Name: "b", OpsPerSec: 1.5, Ops: 3},
+	}
+	if len(s.Queries) != len(want) {
+		t.Fatalf("got %d queries, want %d", len(s.Queries), len(want))
+	}
+	for i, q := range s.Queries {
+		if q.Name != want[i].Name || q.OpsPerSec != want[i].OpsPerSec || q.Ops != want[i].Ops || q.Errors != want[i].Errors {
+			t.Errorf("query %d: got %+v, want %+v", i, q, want[i])
+		}
+		if q.P50 <= 0 || q.P50 > q.P95 || q.P95 > q.P99 {
+			t.Errorf("query %v: got percentiles %v, %v, %v", q.Name, q.P50, q.P95, q.P99)
+		}
+	}
+
+	// the rate is computed since the previous snapshot, the totals over the run
+	s = m.snapshot(start.Add(3 * time.Second))
+	for _, q := range s.Queries {
+		if q.OpsPerSec != 0 || q.Ops == 0 {
+			t.Errorf("query %v: got %v ops/sec and %d ops after an idle second", q.Name, q.OpsPerSec, q.Ops)
+		}
+	}
+}
+
+func TestLiveMonitorShow(t *testing.T) {
+	tests := []struct {
+		tty      bool
+		want     []string
+		wantANSI bool
+	}{
+		{
+			tty:  false,
+			want: []string{"elapsed: 2s, workers: 2, queue depth: 7", "b: 1.5 ops/sec, total: 3"},
+		},
+		{
+			tty:      true,
+			want:     []string{"Elapsed: 2s    Workers: 2    Queue depth: 7", "QUERY  OPS/SEC  TOTAL", "\nb      1.5      3 "},
+			wantANSI: true,
+		},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		m, start := newTestLiveMonitor(&b, tt.tty)
+		m.show(m.snapshot(start.Add(2 * time.Second)))
+		out := b.String()
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("tty %v: output %q does not contain %q", tt.tty, out, want)
+			}
+		}
+		if got := strings.Contains(out, "\033"); got != tt.wantANSI {
+			t.Errorf("tty %v: got ANSI escapes %v in %q", tt.tty, got, out)
+		}
+	}
+}
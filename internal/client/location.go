@@ -0,0 +1,154 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pathElemRe matches the keys and the [index] of a path such as Queries[0].Meta.Filter.
+var pathElemRe = regexp.MustCompile(`[^.\[\]]+|\[\d+\]`)
+
+// locator finds the line numbers of the keys of a scenario from its YAML
+// nodes, so that flow style collections, aliases and merge keys are located.
+type locator struct {
+	root  *yaml.Node
+	lines int
+}
+
+// newLocator parses the scenario. Lines are unknown when it cannot be parsed.
+func newLocator(b []byte) *locator {
+	l := &locator{lines: strings.Count(string(b), "\n") + 1}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err == nil {
+		l.root = resolveNode(&doc)
+	}
+	return l
+}
+
+// line returns the number of the line declaring the last key or item
+// of the path, or the line of its closest parent found, or def.
+// Keys are matched ignoring case, as mapstructure does for Meta.
+func (l *locator) line(path string, def int) int {
+	n, line := l.root, def
+	for _, elem := range pathElemRe.FindAllString(path, -1) {
+		var key *yaml.Node
+		if strings.HasPrefix(elem, "[") {
+			i, _ := strconv.Atoi(elem[1 : len(elem)-1])
+			key, n = sequenceItem(n, i)
+		} else {
+			key, n = mappingValue(n, elem)
+		}
+		if key == nil {
+			break
+		}
+		line = key.Line
+	}
+	return line
+}
+
+// queryLine returns the line of the query at index i, or 0 if unknown.
+func (l *locator) queryLine(i int) int {
+	return l.line(fmt.Sprintf("Queries[%d]", i), 0)
+}
+
+// queryKeyLine returns the line of the key of the query at index i,
+// or the line of the query if not found.
+func (l *locator) queryKeyLine(i int, path string) int {
+	start := l.queryLine(i)
+	if path == "" {
+		return start
+	}
+	return l.line(fmt.Sprintf("Queries[%d].%v", i, path), start)
+}
+
+// queriesSection returns the index of the Queries line and the index
+// of the line following the section, or -1 and the number of lines
+// if the section cannot be delimited, such as in a flow style document.
+func (l *locator) queriesSection() (int, int) {
+	root := l.root
+	if root == nil || root.Kind != yaml.MappingNode || root.Style&yaml.FlowStyle != 0 {
+		return -1, l.lines
+	}
+	start := -1
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if start >= 0 && key.Line > start+1 {
+			return start, key.Line - 1
+		}
+		if key.Value == "Queries" {
+			start = key.Line - 1
+		}
+	}
+	return start, l.lines
+}
+
+// resolveNode returns the node referenced by an alias,
+// or the content of a document.
+func resolveNode(n *yaml.Node) *yaml.Node {
+	for n != nil {
+		switch {
+		case n.Kind == yaml.AliasNode:
+			n = n.Alias
+		case n.Kind == yaml.DocumentNode && len(n.Content) > 0:
+			n = n.Content[0]
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the key node and the value of the key of the mapping,
+// preferring an exact match and searching the mappings merged using <<.
+func mappingValue(n *yaml.Node, name string) (*yaml.Node, *yaml.Node) {
+	n = resolveNode(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	var key, value *yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k := n.Content[i]
+		if k.Value == name {
+			return k, n.Content[i+1]
+		}
+		if key == nil && strings.EqualFold(k.Value, name) {
+			key, value = k, n.Content[i+1]
+		}
+	}
+	if key != nil {
+		return key, value
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value != "<<" {
+			continue
+		}
+		merged := resolveNode(n.Content[i+1])
+		if merged == nil {
+			continue
+		}
+		sources := []*yaml.Node{merged}
+		if merged.Kind == yaml.SequenceNode {
+			sources = merged.Content
+		}
+		for _, s := range sources {
+			if key, value := mappingValue(s, name); key != nil {
+				return key, value
+			}
+		}
+	}
+	return nil, nil
+}
+
+// sequenceItem returns the item at index i of the sequence, twice,
+// as an item is located by its own line.
+func sequenceItem(n *yaml.Node, i int) (*yaml.Node, *yaml.Node) {
+	n = resolveNode(n)
+	if n == nil || n.Kind != yaml.SequenceNode || i >= len(n.Content) {
+		return nil, nil
+	}
+	return n.Content[i], n.Content[i]
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"mongoperf/internal/client/query"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// prometheusBuckets are the upper bounds, in seconds,
+// of the query duration histogram.
+var prometheusBuckets = []float64{
+	0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+type prometheusQuery struct {
+	action  string
+	ops     int64
+	errors  map[string]int64
+	buckets []int64
+	sum     float64
+}
+
+// PrometheusMetrics is an Observer exposing live run
+// metrics using the Prometheus text exposition format.
+type PrometheusMetrics struct {
+	mu            sync.Mutex
+	queries       map[string]*prometheusQuery
+	activeWorkers int
+}
+
+// NewPrometheusMetrics .
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{queries: make(map[string]*prometheusQuery)}
+}
+
+// WorkerStarted implements the Observer interface.
+func (m *PrometheusMetrics) WorkerStarted(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeWorkers++
+}
+
+// WorkerStopped implements the Observer interface.
+func (m *PrometheusMetrics) WorkerStopped(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeWorkers--
+}
+
+// Observe implements the Observer interface.
+func (m *PrometheusMetrics) Observe(result *query.Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name := *result.Definition.Name
+	q, ok := m.queries[name]
+	if !ok {
+		q = &prometheusQuery{
+			action:  string(*result.Definition.Action),
+			errors:  make(map[string]int64),
+			buckets: make([]int64, len(prometheusBuckets)),
+		}
+		m.queries[name] = q
+	}
+	seconds := result.Latency().Seconds()
+	q.ops++
+	q.sum += seconds
+	for i, le := range prometheusBuckets {
+		if seconds <= le {
+			q.buckets[i]++
+		}
+	}
+	if result.Error != nil {
+		q.errors[ErrorClass(result.Error)]++
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := m.Write(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Write writes the current metrics.
+func (m *PrometheusMetrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.queries))
+	for name := range m.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# HELP mongoperf_active_workers Number of workers currently processing queries.\n")
+	b.WriteString("# TYPE mongoperf_active_workers gauge\n")
+	fmt.Fprintf(&b, "mongoperf_active_workers %d\n", m.activeWorkers)
+
+	b.WriteString("# HELP mongoperf_queries_total Number of queries completed.\n")
+	b.WriteString("# TYPE mongoperf_queries_total counter\n")
+	for _, name := range names {
+		q := m.queries[name]
+		fmt.Fprintf(&b, "mongoperf_queries_total{%v} %d\n", promLabels("query", name, "action", q.action), q.ops)
+	}
+
+	b.WriteString("# HELP mongoperf_query_errors_total Number of queries which failed, by error class.\n")
+	b.WriteString("# TYPE mongoperf_query_errors_total counter\n")
+	for _, name := range names {
+		q := m.queries[name]
+		classes := make([]string, 0, len(q.errors))
+		for class := range q.errors {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		for _, class := range classes {
+			fmt.Fprintf(&b, "mongoperf_query_errors_total{%v} %d\n", promLabels("query", name, "class", class), q.errors[class])
+		}
+	}
+
+	b.WriteString("# HELP mongoperf_query_duration_seconds Query latency.\n")
+	b.WriteString("# TYPE mongoperf_query_duration_seconds histogram\n")
+	for _, name := range names {
+		q := m.queries[name]
+		for i, le := range prometheusBuckets {
+			le := strconv.FormatFloat(le, 'g', -1, 64)
+			fmt.Fprintf(&b, "mongoperf_query_duration_seconds_bucket{%v} %d\n", promLabels("query", name, "le", le), q.buckets[i])
+		}
+		fmt.Fprintf(&b, "mongoperf_query_duration_seconds_bucket{%v} %d\n", promLabels("query", name, "le", "+Inf"), q.ops)
+		fmt.Fprintf(&b, "mongoperf_query_duration_seconds_sum{%v} %v\n", promLabels("query", name), q.sum)
+		fmt.Fprintf(&b, "mongoperf_query_duration_seconds_count{%v} %d\n", promLabels("query", name), q.ops)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// promLabels formats label pairs, escaping their values.
+func promLabels(kv ...string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%v="%v"`, kv[i], escaper.Replace(kv[i+1])))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"mongoperf/internal/client/query"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetricsWrite(t *testing.T) {
+	defer func(b []float64) { prometheusBuckets = b }(prometheusBuckets)
+	prometheusBuckets = []float64{0.5, 1}
+
+	definition := func(name string, action query.Action) *query.Definition {
+		return &query.Definition{Name: &name, Action: &action}
+	}
+	find := definition("find \"a\"\\b\n", query.FindAction)
+	insert := definition("insert", query.InsertOneAction)
+	start := time.Now()
+	results := []*query.Result{
+		{Definition: find, Start: start, End: start.Add(250 * time.Millisecond)},
+		{Definition: find, Start: start, End: start.Add(500 * time.Millisecond), Error: context.DeadlineExceeded},
+		{Definition: find, Start: start, End: start.Add(2 * time.Second), Error: context.DeadlineExceeded},
+		{Definition: insert, Start: start, End: start.Add(time.Second), Error: errors.New("failed")},
+	}
+
+	m := NewPrometheusMetrics()
+	for id := 0; id < 3; id++ {
+		m.WorkerStarted(id)
+	}
+	m.WorkerStopped(1)
+	for _, r := range results {
+		m.Observe(r)
+	}
+	var b strings.Builder
+	if err := m.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP mongoperf_active_workers Number of workers currently processing queries.
+# TYPE mongoperf_active_workers gauge
+mongoperf_active_workers 2
+# HELP mongoperf_queries_total Number of queries completed.
+# TYPE mongoperf_queries_total counter
+mongoperf_queries_total{query="find \"a\"\\b\n",action="Find"} 3
+mongoperf_queries_total{query="insert",action="InsertOne"} 1
+# HELP mongoperf_query_errors_total Number of queries which failed, by error class.
+# TYPE mongoperf_query_errors_total counter
+mongoperf_query_errors_total{query="find \"a\"\\b\n",class="timeout"} 2
+mongoperf_query_errors_total{query="insert",class="other"} 1
+# HELP mongoperf_query_duration_seconds Query latency.
+# TYPE mongoperf_query_duration_seconds histogram
+mongoperf_query_duration_seconds_bucket{query="find \"a\"\\b\n",le="0.5"} 2
+mongoperf_query_duration_seconds_bucket{query="find \"a\"\\b\n",le="1"} 2
+mongoperf_query_duration_seconds_bucket{query="find \"a\"\\b\n",le="+Inf"} 3
+mongoperf_query_duration_seconds_sum{query="find \"a\"\\b\n"} 2.75
+mongoperf_query_duration_seconds_count{query="find \"a\"\\b\n"} 3
+mongoperf_query_duration_seconds_bucket{query="insert",le="0.5"} 0
+mongoperf_query_duration_seconds_bucket{query="insert",le="1"} 1
+mongoperf_query_duration_seconds_bucket{query="insert",le="+Inf"} 1
+mongoperf_query_duration_seconds_sum{query="insert"} 1
+mongoperf_query_duration_seconds_count{query="insert"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
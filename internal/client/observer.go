@@ -0,0 +1,24 @@
+package client
+
+import "mongoperf/internal/client/query"
+
+// Observer is notified of the progress of a scenario run.
+//
+// Results are observed from the result aggregation goroutine,
+// one at a time, as they complete. Worker notifications are
+// sent from the worker goroutines.
+type Observer interface {
+	WorkerStarted(id int)
+	WorkerStopped(id int)
+	Observe(result *query.Result)
+}
+
+// WithObserver registers an Observer notified during scenario runs.
+func WithObserver(o Observer) func(c *Client) {
+	if o != nil {
+		return func(c *Client) {
+			c.observers = append(c.observers, o)
+		}
+	}
+	return func(c *Client) {}
+}
@@ -0,0 +1,397 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// variableRe matches ${name} and ${name:-default} references,
+// as well as $$ which escapes a dollar sign.
+var variableRe = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_.]*)(:-([^}]*))?\}`)
+
+// plainScalarRe matches the values which can be inserted
+// as is in an unquoted YAML value.
+var plainScalarRe = regexp.MustCompile(`^[A-Za-z0-9_./+=~()@:-]+( [A-Za-z0-9_./+=~()@:-]+)*$`)
+
+// VariableError is a reference to a variable which cannot be replaced.
+type VariableError struct {
+	Line    int
+	Name    string
+	Message string
+}
+
+func (e VariableError) Error() string {
+	return fmt.Sprintf("line %d: variable %v %v", e.Line, e.Name, e.Message)
+}
+
+// VariableErrors holds the references which cannot be replaced.
+type VariableErrors []VariableError
+
+func (e VariableErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ParseParams parses parameters formatted as key=value.
+func ParseParams(values []string) (map[string]string, error) {
+	params := make(map[string]string, len(values))
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("parameter must be formatted as key=value: %v", v)
+		}
+		params[parts[0]] = parts[1]
+	}
+	return params, nil
+}
+
+// InterpolateScenario replaces the variables referenced in the scenario
+// before it is unmarshalled. A variable is resolved, in order, from the
+// provided values, the Params block of the scenario, the environment,
+// and the default of the reference. $$ is replaced by $.
+//
+// References in comments are left as is. Values are quoted or escaped
+// so that they cannot change the structure of the document. The provided
+// values must be declared in Params or referenced by the scenario.
+//
+// Params values can themselves reference other parameters, the provided
+// values and the environment; the provided values are used as is.
+// The resolved parameters are returned along with the scenario.
+// On VariableErrors, the scenario is returned with the references
+// which cannot be replaced left as is.
+func InterpolateScenario(b []byte, set map[string]string) ([]byte, map[string]string, error) {
+	// READ PARAMS
+	// references are masked, so that the YAML can be parsed
+	// whatever their value, and restored in the Params values
+	masked, refs := maskReferences(string(b))
+	var header struct {
+		Params map[string]interface{} `yaml:"Params"`
+	}
+	if err := yaml.Unmarshal([]byte(masked), &header); err != nil {
+		return nil, nil, err
+	}
+	raw := make(map[string]string, len(header.Params))
+	for k, v := range header.Params {
+		raw[k] = unmaskReferences(fmt.Sprint(v), refs)
+	}
+	referenced := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		referenced[variableRe.FindStringSubmatch(ref)[1]] = true
+	}
+	for k := range set {
+		if _, ok := raw[k]; !ok && !referenced[k] {
+			return nil, nil, fmt.Errorf("parameter %v is neither declared in Params nor referenced by the scenario", k)
+		}
+	}
+
+	// RESOLVE PARAMS
+	r := paramResolver{raw: raw, set: set, resolved: make(map[string]string, len(raw)+len(set))}
+	for k, v := range set {
+		r.resolved[k] = v
+	}
+	names := make([]string, 0, len(raw))
+	for k := range raw {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if _, err := r.resolve(k); err != nil {
+			err.Line = newLocator([]byte(masked)).line("Params."+err.Name, 0)
+			return nil, nil, err
+		}
+	}
+	params := r.resolved
+
+	// INTERPOLATE
+	lookupParam := func(name string) (string, bool) {
+		if v, ok := params[name]; ok {
+			return v, true
+		}
+		return os.LookupEnv(name)
+	}
+	out, err := interpolate(string(b), lookupParam, nil)
+	return []byte(out), params, err
+}
+
+// paramResolver resolves the Params values referencing other parameters.
+type paramResolver struct {
+	raw      map[string]string
+	set      map[string]string
+	resolved map[string]string
+	stack    []string
+}
+
+// resolve returns the value of the parameter, replacing its references.
+// Undefined references are left as is, to be reported with their line
+// when the Params block is interpolated.
+func (r *paramResolver) resolve(name string) (string, *VariableError) {
+	if v, ok := r.resolved[name]; ok {
+		return v, nil
+	}
+	for i, n := range r.stack {
+		if n == name {
+			cycle := append(r.stack[i+1:len(r.stack):len(r.stack)], name)
+			return "", &VariableError{Name: name, Message: "references itself through " + strings.Join(cycle, ", ")}
+		}
+	}
+	r.stack = append(r.stack, name)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	s := r.raw[name]
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, loc := range variableRe.FindAllStringSubmatchIndex(s, -1) {
+		start, end := loc[0], loc[1]
+		b.WriteString(s[last:start])
+		last = end
+		ref := s[start:end]
+		if ref == "$$" {
+			b.WriteString("$")
+			continue
+		}
+		n := s[loc[2]:loc[3]]
+		if _, ok := r.raw[n]; ok {
+			v, err := r.resolve(n)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+		} else if v, ok := os.LookupEnv(n); ok {
+			b.WriteString(v)
+		} else if loc[4] >= 0 {
+			b.WriteString(s[loc[6]:loc[7]])
+		} else {
+			b.WriteString(ref)
+		}
+	}
+	b.WriteString(s[last:])
+	r.resolved[name] = b.String()
+	return r.resolved[name], nil
+}
+
+// maskReferences replaces the references outside of comments by tokens
+// which are valid in any YAML context, and returns the references
+// indexed by the number of their token.
+func maskReferences(s string) (string, []string) {
+	lines := strings.Split(s, "\n")
+	var refs []string
+	for i, line := range lines {
+		contexts := yamlContexts(line)
+		lines[i] = replaceMatches(line, func(start, end int) string {
+			ref := line[start:end]
+			if ref == "$$" || contexts[start] == commentContext {
+				return ref
+			}
+			refs = append(refs, ref)
+			return fmt.Sprintf("mongoperfref%dx", len(refs)-1)
+		})
+	}
+	return strings.Join(lines, "\n"), refs
+}
+
+// maskTokenRe matches the tokens of maskReferences.
+var maskTokenRe = regexp.MustCompile(`mongoperfref(\d+)x`)
+
+// unmaskReferences restores the references masked by maskReferences.
+func unmaskReferences(s string, refs []string) string {
+	return maskTokenRe.ReplaceAllStringFunc(s, func(token string) string {
+		i, _ := strconv.Atoi(maskTokenRe.FindStringSubmatch(token)[1])
+		return refs[i]
+	})
+}
+
+// replaceMatches replaces the references of the line by the result of f.
+func replaceMatches(line string, f func(start, end int) string) string {
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, loc := range variableRe.FindAllStringIndex(line, -1) {
+		b.WriteString(line[last:loc[0]])
+		b.WriteString(f(loc[0], loc[1]))
+		last = loc[1]
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// referencedVariables returns the names of the variables
+// referenced by the scenario, outside of comments.
+func referencedVariables(b []byte) map[string]bool {
+	referenced := make(map[string]bool)
+	record := func(name string) (string, bool) {
+		referenced[name] = true
+		return "x", true
+	}
+	interpolate(string(b), record, nil)
+	return referenced
+}
+
+// interpolate replaces the references using lookup. Undefined
+// references without default are resolved by fallback, if any,
+// and reported otherwise.
+func interpolate(s string, lookup, fallback func(string) (string, bool)) (string, error) {
+	lines := strings.Split(s, "\n")
+	var errs VariableErrors
+	for i, line := range lines {
+		contexts := yamlContexts(line)
+		var (
+			b    strings.Builder
+			last int
+		)
+		for _, loc := range variableRe.FindAllStringSubmatchIndex(line, -1) {
+			start, end := loc[0], loc[1]
+			ctx := contexts[start]
+			if ctx == commentContext {
+				continue
+			}
+			b.WriteString(line[last:start])
+			last = end
+			ref := line[start:end]
+			if ref == "$$" {
+				b.WriteString("$")
+				continue
+			}
+			name := line[loc[2]:loc[3]]
+			v, ok := lookup(name)
+			if !ok && loc[4] >= 0 {
+				v, ok = line[loc[6]:loc[7]], true
+			}
+			if !ok && fallback != nil {
+				v, ok = fallback(name)
+			}
+			if !ok {
+				errs = append(errs, VariableError{Line: i + 1, Name: name, Message: "is not defined"})
+				b.WriteString(ref)
+				continue
+			}
+			encoded, err := encodeValue(v, ctx, line, start, end)
+			if err != nil {
+				errs = append(errs, VariableError{Line: i + 1, Name: name, Message: err.Error()})
+				b.WriteString(ref)
+				continue
+			}
+			b.WriteString(encoded)
+		}
+		b.WriteString(line[last:])
+		lines[i] = b.String()
+	}
+	if len(errs) > 0 {
+		return strings.Join(lines, "\n"), errs
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// YAML contexts of the characters of a line .
+const (
+	plainContext = iota
+	singleQuotedContext
+	doubleQuotedContext
+	commentContext
+)
+
+// yamlContexts returns the context of every byte of the line. Quotes
+// only start a string at the beginning of a value, and comments start
+// with a # at the beginning of the line or after a space.
+func yamlContexts(line string) []int {
+	contexts := make([]int, len(line))
+	ctx := plainContext
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch ctx {
+		case plainContext:
+			switch {
+			case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+				for j := i; j < len(line); j++ {
+					contexts[j] = commentContext
+				}
+				return contexts
+			case (c == '\'' || c == '"') && valueStart(line[:i]):
+				contexts[i] = plainContext
+				if c == '\'' {
+					ctx = singleQuotedContext
+				} else {
+					ctx = doubleQuotedContext
+				}
+				continue
+			}
+		case singleQuotedContext:
+			if c == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					contexts[i], contexts[i+1] = ctx, ctx
+					i++
+					continue
+				}
+				contexts[i] = ctx
+				ctx = plainContext
+				continue
+			}
+		case doubleQuotedContext:
+			if c == '\\' && i+1 < len(line) {
+				contexts[i], contexts[i+1] = ctx, ctx
+				i++
+				continue
+			}
+			if c == '"' {
+				contexts[i] = ctx
+				ctx = plainContext
+				continue
+			}
+		}
+		contexts[i] = ctx
+	}
+	return contexts
+}
+
+// valueStart reports whether a value starts after the prefix.
+func valueStart(prefix string) bool {
+	p := strings.TrimRight(prefix, " \t")
+	if p == "" || strings.HasSuffix(p, "[") || strings.HasSuffix(p, "{") || strings.HasSuffix(p, ",") {
+		return true
+	}
+	spaced := len(p) < len(prefix)
+	return spaced && (strings.HasSuffix(p, ":") || strings.HasSuffix(p, "-") || strings.HasSuffix(p, "?"))
+}
+
+// valueEnd reports whether a value ends before the suffix.
+func valueEnd(suffix string) bool {
+	s := strings.TrimLeft(suffix, " \t")
+	if s == "" || s[0] == ',' || s[0] == ']' || s[0] == '}' {
+		return true
+	}
+	return s[0] == '#' && len(s) < len(suffix)
+}
+
+// encodeValue returns the value escaped for the context of the reference,
+// found between start and end of the line.
+func encodeValue(v string, ctx int, line string, start, end int) (string, error) {
+	switch ctx {
+	case doubleQuotedContext:
+		q := strconv.Quote(v)
+		return q[1 : len(q)-1], nil
+	case singleQuotedContext:
+		if strings.ContainsAny(v, "\r\n") {
+			return "", fmt.Errorf("cannot contain a line break in a single-quoted string")
+		}
+		return strings.Replace(v, "'", "''", -1), nil
+	}
+	if plainScalarRe.MatchString(v) && !strings.Contains(v, ": ") && !strings.HasSuffix(v, ":") &&
+		!strings.HasPrefix(v, ":") && !strings.HasPrefix(v, "@") {
+		return v, nil
+	}
+	if valueStart(line[:start]) && valueEnd(line[end:]) {
+		return strconv.Quote(v), nil
+	}
+	return "", fmt.Errorf("has a value which must be quoted in the scenario: %q", v)
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"os"
+	"testing"
+)
+
+func TestInterpolateScenario(t *testing.T) {
+	os.Setenv("MONGOPERF_TEST_DB", "perf")
+	defer os.Unsetenv("MONGOPERF_TEST_DB")
+
+	tests := []struct {
+		name    string
+		in      string
+		set     map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "environment",
+			in:   "Database: ${MONGOPERF_TEST_DB}",
+			want: "Database: perf",
+		},
+		{
+			name: "default",
+			in:   "Parallel: ${MONGOPERF_TEST_UNSET:-4}",
+			want: "Parallel: 4",
+		},
+		{
+			name: "params",
+			in:   "Params:\n  workers: 2\nParallel: ${workers}",
+			want: "Params:\n  workers: 2\nParallel: 2",
+		},
+		{
+			name: "set overrides params",
+			in:   "Params:\n  workers: 2\nParallel: ${workers}",
+			set:  map[string]string{"workers": "8"},
+			want: "Params:\n  workers: 2\nParallel: 8",
+		},
+		{
+			name: "set overrides environment",
+			in:   "Database: ${MONGOPERF_TEST_DB}",
+			set:  map[string]string{"MONGOPERF_TEST_DB": "other"},
+			want: "Database: other",
+		},
+		{
+			name: "escaped dollar",
+			in:   "Filter: {price: $$5}",
+			want: "Filter: {price: $5}",
+		},
+		{
+			name: "comment",
+			in:   "# use ${MONGOPERF_TEST_UNSET}\nRepeat: 1 # or ${MONGOPERF_TEST_UNSET}",
+			want: "# use ${MONGOPERF_TEST_UNSET}\nRepeat: 1 # or ${MONGOPERF_TEST_UNSET}",
+		},
+		{
+			name: "hash inside a value",
+			in:   "Name: a#${MONGOPERF_TEST_DB}",
+			want: "Name: a#perf",
+		},
+		{
+			name: "quoted value",
+			in:   "Name: ${name}",
+			set:  map[string]string{"name": "a: b\nc: d"},
+			want: `Name: "a: b\nc: d"`,
+		},
+		{
+			name: "quoted flow value",
+			in:   "Filter: {name: ${name}}",
+			set:  map[string]string{"name": "{x: 1}"},
+			want: `Filter: {name: "{x: 1}"}`,
+		},
+		{
+			name: "empty value",
+			in:   "Name: ${name}",
+			set:  map[string]string{"name": ""},
+			want: `Name: ""`,
+		},
+		{
+			name: "double-quoted string",
+			in:   `Name: "user ${name}"`,
+			set:  map[string]string{"name": "\"a\"\n"},
+			want: `Name: "user \"a\"\n"`,
+		},
+		{
+			name: "single-quoted string",
+			in:   `Name: 'user ${name}'`,
+			set:  map[string]string{"name": "it's"},
+			want: `Name: 'user it''s'`,
+		},
+		{
+			name: "apostrophe in a plain value",
+			in:   "Name: it's ${MONGOPERF_TEST_DB}",
+			want: "Name: it's perf",
+		},
+		{
+			name: "params referencing params",
+			in:   "Params:\n  db: ${MONGOPERF_TEST_DB}\n  coll: \"${db}_c\"\nCollection: ${coll}",
+			want: "Params:\n  db: perf\n  coll: \"perf_c\"\nCollection: perf_c",
+		},
+		{
+			name: "set propagates to params",
+			in:   "Params:\n  db: perf\n  coll: ${db}_c\nCollection: ${coll}",
+			set:  map[string]string{"db": "other"},
+			want: "Params:\n  db: perf\n  coll: other_c\nCollection: other_c",
+		},
+		{
+			name: "set values are not resolved",
+			in:   "Params:\n  db: perf\nCollection: ${coll}",
+			set:  map[string]string{"coll": "${db}"},
+			want: "Params:\n  db: perf\nCollection: \"${db}\"",
+		},
+		{
+			name:    "params referencing each other",
+			in:      "Params:\n  a: ${b}\n  b: x${a}\nCollection: ${a}",
+			wantErr: "line 2: variable a references itself through b, a",
+		},
+		{
+			name:    "undefined in params",
+			in:      "Params:\n  coll: ${MONGOPERF_TEST_UNSET}_c\nCollection: ${coll}",
+			wantErr: "line 2: variable MONGOPERF_TEST_UNSET is not defined",
+		},
+		{
+			name:    "undefined",
+			in:      "Database: test\nRepeat: ${MONGOPERF_TEST_UNSET}",
+			wantErr: "line 2: variable MONGOPERF_TEST_UNSET is not defined",
+		},
+		{
+			name:    "value inside a plain value",
+			in:      "Name: user-${name}",
+			set:     map[string]string{"name": "a: b"},
+			wantErr: `line 1: variable name has a value which must be quoted in the scenario: "a: b"`,
+		},
+		{
+			name:    "unknown parameter",
+			in:      "Params:\n  workers: 2\nParallel: ${workers}",
+			set:     map[string]string{"wokers": "8"},
+			wantErr: "parameter wokers is neither declared in Params nor referenced by the scenario",
+		},
+		{
+			name:    "parameter only referenced in a comment",
+			in:      "# ${workers}\nParallel: 1",
+			set:     map[string]string{"workers": "8"},
+			wantErr: "parameter workers is neither declared in Params nor referenced by the scenario",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, err := InterpolateScenario([]byte(tt.in), tt.set)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("got %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolateScenarioStructure(t *testing.T) {
+	// values cannot add keys to the scenario
+	b := []byte("Database: test\nCollection: ${name}\nQueries:\n- Name: q\n  Action: FindOneAction\n  Meta: {Filter: {}}\n")
+	s, err := ParseScenario(b, map[string]string{"name": "users\nParallel: 64"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *s.Collection != "users\nParallel: 64" || *s.Parallel != 1 {
+		t.Errorf("got Collection %q and Parallel %d", *s.Collection, *s.Parallel)
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		in      []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{in: []string{"a=1", "b=x=y", "c="}, want: map[string]string{"a": "1", "b": "x=y", "c": ""}},
+		{in: []string{"a"}, wantErr: true},
+		{in: []string{"=1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseParams(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseParams(%v): got error %v", tt.in, err)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("ParseParams(%v)[%v]: got %q, want %q", tt.in, k, got[k], v)
+			}
+		}
+	}
+}
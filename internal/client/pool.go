@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// PoolStats holds the connection pool counters collected during a run.
+// CheckOutWait is the time operations waited before their first command
+// was sent, which includes server selection as well as the check out.
+type PoolStats struct {
+	ConnectionsCreated int64         `json:"connectionsCreated"`
+	ConnectionsClosed  int64         `json:"connectionsClosed"`
+	CheckedOut         int64         `json:"checkedOut"`
+	CheckedIn          int64         `json:"checkedIn"`
+	CheckOutFailed     int64         `json:"checkOutFailed"`
+	PoolCleared        int64         `json:"poolCleared"`
+	CheckOutWait       time.Duration `json:"checkOutWait"`
+	CheckOutWaitMax    time.Duration `json:"checkOutWaitMax"`
+	CheckOutWaitCount  int64         `json:"checkOutWaitCount"`
+}
+
+// CheckOutWaitAvg returns the average time an operation waited
+// before its first command was sent on a checked out connection,
+// including the selection of the server.
+func (s PoolStats) CheckOutWaitAvg() time.Duration {
+	if s.CheckOutWaitCount == 0 {
+		return 0
+	}
+	return time.Duration(int64(s.CheckOutWait) / s.CheckOutWaitCount)
+}
+
+// poolMonitor records pool events emitted by the driver.
+//
+// The driver does not publish an event when a checkout starts, so the
+// checkout wait is measured from the moment an operation is handed to
+// the driver (see withCheckOutTimer) until its first command is started,
+// which only happens once a server has been selected and a connection
+// checked out. The wait thus includes server selection.
+type poolMonitor struct {
+	mu    sync.Mutex
+	stats PoolStats
+}
+
+func newPoolMonitor() *poolMonitor {
+	return &poolMonitor{}
+}
+
+func (m *poolMonitor) poolEvent(e *event.PoolEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch e.Type {
+	case event.ConnectionCreated:
+		m.stats.ConnectionsCreated++
+	case event.ConnectionClosed:
+		m.stats.ConnectionsClosed++
+	case event.GetSucceeded:
+		m.stats.CheckedOut++
+	case event.GetFailed:
+		m.stats.CheckOutFailed++
+	case event.ConnectionReturned:
+		m.stats.CheckedIn++
+	case event.PoolCleared:
+		m.stats.PoolCleared++
+	}
+}
+
+func (m *poolMonitor) commandStarted(ctx context.Context, e *event.CommandStartedEvent) {
+	t, ok := ctx.Value(checkOutTimerKey{}).(*checkOutTimer)
+	if !ok || !atomic.CompareAndSwapInt32(&t.done, 0, 1) {
+		return
+	}
+	wait := time.Since(t.start)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.CheckOutWait += wait
+	m.stats.CheckOutWaitCount++
+	if wait > m.stats.CheckOutWaitMax {
+		m.stats.CheckOutWaitMax = wait
+	}
+}
+
+// Snapshot returns a copy of the current counters.
+func (m *poolMonitor) Snapshot() PoolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// PoolMonitor returns the driver pool monitor.
+func (m *poolMonitor) PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{Event: m.poolEvent}
+}
+
+// CommandMonitor returns the driver command monitor.
+func (m *poolMonitor) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{Started: m.commandStarted}
+}
+
+type checkOutTimerKey struct{}
+
+type checkOutTimer struct {
+	start time.Time
+	done  int32
+}
+
+// withCheckOutTimer returns a context used to measure how long
+// the next operation waits for a connection.
+func withCheckOutTimer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, checkOutTimerKey{}, &checkOutTimer{start: time.Now()})
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestPoolMonitor(t *testing.T) {
+	m := newPoolMonitor()
+	pool := m.PoolMonitor()
+	for _, typ := range []string{
+		event.PoolCreated, event.ConnectionCreated, event.ConnectionCreated,
+		event.GetSucceeded, event.GetSucceeded, event.GetFailed,
+		event.ConnectionReturned, event.ConnectionClosed, event.PoolCleared, event.PoolClosedEvent,
+	} {
+		pool.Event(&event.PoolEvent{Type: typ, Address: "localhost:27017"})
+	}
+
+	// the wait is only recorded for the first command of an operation
+	commands := m.CommandMonitor()
+	ctx := withCheckOutTimer(context.Background())
+	time.Sleep(5 * time.Millisecond)
+	commands.Started(ctx, &event.CommandStartedEvent{CommandName: "find"})
+	commands.Started(ctx, &event.CommandStartedEvent{CommandName: "getMore"})
+	commands.Started(context.Background(), &event.CommandStartedEvent{CommandName: "isMaster"})
+	commands.Started(withCheckOutTimer(context.Background()), &event.CommandStartedEvent{CommandName: "insert"})
+
+	got := m.Snapshot()
+	want := PoolStats{
+		ConnectionsCreated: 2,
+		ConnectionsClosed:  1,
+		CheckedOut:         2,
+		CheckedIn:          1,
+		CheckOutFailed:     1,
+		PoolCleared:        1,
+		CheckOutWaitCount:  2,
+	}
+	if got.CheckOutWaitMax < 5*time.Millisecond || got.CheckOutWait < got.CheckOutWaitMax {
+		t.Errorf("got wait %v and max %v, want a max of at least 5ms", got.CheckOutWait, got.CheckOutWaitMax)
+	}
+	if avg := got.CheckOutWaitAvg(); avg != got.CheckOutWait/2 {
+		t.Errorf("got average wait %v, want %v", avg, got.CheckOutWait/2)
+	}
+	got.CheckOutWait, got.CheckOutWaitMax = 0, 0
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if (PoolStats{}).CheckOutWaitAvg() != 0 {
+		t.Errorf("got a non zero average wait without operation")
+	}
+}
@@ -0,0 +1,253 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"mongoperf/internal/client/query"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// unattributedProfile is the name used for profiled operations
+// which could not be matched to a scenario query.
+const unattributedProfile = "<unattributed>"
+
+// EnableProfiler sets the profiling level and slowms threshold of the
+// scenario database and returns a function restoring the previous settings.
+func (c *Client) EnableProfiler(ctx context.Context, scenario *Scenario, level, slowms int) (func(context.Context) error, error) {
+	if level < 0 || level > 2 {
+		return nil, fmt.Errorf("profiling level must be 0, 1 or 2")
+	}
+	db := c.client.Database(*scenario.Database)
+	var previous struct {
+		Was    int `bson:"was"`
+		SlowMS int `bson:"slowms"`
+	}
+	cmd := bson.D{{Key: "profile", Value: level}, {Key: "slowms", Value: slowms}}
+	if err := db.RunCommand(ctx, cmd).Decode(&previous); err != nil {
+		return nil, err
+	}
+	c.logger.Infof("profiling level set to %d (slowms: %d)", level, slowms)
+	restore := func(ctx context.Context) error {
+		cmd := bson.D{{Key: "profile", Value: previous.Was}, {Key: "slowms", Value: previous.SlowMS}}
+		if err := db.RunCommand(ctx, cmd).Err(); err != nil {
+			return err
+		}
+		c.logger.Infof("profiling level restored to %d (slowms: %d)", previous.Was, previous.SlowMS)
+		return nil
+	}
+	return restore, nil
+}
+
+// ReportProfile .
+type ReportProfile struct {
+	Name           string  `json:"name"`
+	Ops            int     `json:"ops"`
+	TotalMillis    int64   `json:"totalMillis"`
+	AvgMillis      float64 `json:"avgMillis"`
+	MaxMillis      int64   `json:"maxMillis"`
+	LockWaitMicros int64   `json:"lockWaitMicros"`
+	PlanSummary    string  `json:"planSummary"`
+}
+
+// ServerTime returns the local time of the server, so that the profiler
+// entries of a run are selected whatever the clock skew of the client.
+func (c *Client) ServerTime(ctx context.Context) (time.Time, error) {
+	var res struct {
+		LocalTime time.Time `bson:"localTime"`
+	}
+	cmd := bson.D{{Key: "isMaster", Value: 1}}
+	if err := c.client.Database("admin").RunCommand(ctx, cmd).Decode(&res); err != nil {
+		return time.Time{}, err
+	}
+	return res.LocalTime, nil
+}
+
+// ProfileStats reads system.profile entries of the scenario collection
+// recorded since the provided server time and aggregates them per
+// scenario query.
+//
+// Operations are matched to queries using their comment, or the $comment
+// of their filter. Operations without comment, such as inserts, are matched
+// using their type when a single query of the scenario performs that type
+// of operation. The operations of transactions are rolled up to their query.
+func (c *Client) ProfileStats(ctx context.Context, scenario *Scenario, since time.Time) ([]*ReportProfile, error) {
+	db := c.client.Database(*scenario.Database)
+	ns := *scenario.Database + "." + *scenario.Collection
+
+	opQueries := make(map[string][]string)
+	transactions := make(map[string]bool)
+	for _, def := range scenario.Queries {
+		for _, op := range profileOps(def) {
+			opQueries[op] = append(opQueries[op], *def.Name)
+		}
+		if *def.Action == query.TransactionAction {
+			transactions[*def.Name] = true
+		}
+	}
+
+	filter := bson.D{
+		{Key: "ns", Value: ns},
+		{Key: "ts", Value: bson.D{{Key: "$gte", Value: since}}},
+	}
+	cur, err := db.Collection("system.profile").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	profiles := make(map[string]*ReportProfile)
+	plans := make(map[string]map[string]int)
+	for cur.Next(ctx) {
+		var entry bson.M
+		if err := cur.Decode(&entry); err != nil {
+			return nil, err
+		}
+		name := profileQueryName(entry, opQueries, transactions)
+		p, ok := profiles[name]
+		if !ok {
+			p = &ReportProfile{Name: name}
+			profiles[name] = p
+			plans[name] = make(map[string]int)
+		}
+		millis := query.ToInt64(entry["millis"])
+		p.Ops++
+		p.TotalMillis += millis
+		if millis > p.MaxMillis {
+			p.MaxMillis = millis
+		}
+		p.LockWaitMicros += profileLockWait(entry)
+		if plan, ok := entry["planSummary"].(string); ok {
+			plans[name][plan]++
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	var result []*ReportProfile
+	for name, p := range profiles {
+		p.AvgMillis = float64(p.TotalMillis) / float64(p.Ops)
+		p.PlanSummary = mostFrequent(plans[name])
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// profileOperationRe matches the names of the operations
+// of transactions, which are their query name followed by [index].
+var profileOperationRe = regexp.MustCompile(`^(.*)\[\d+\]$`)
+
+// profileOps returns the profiler op types of a query, once each.
+func profileOps(def query.Definition) []string {
+	if *def.Action != query.TransactionAction {
+		return []string{profileOp(*def.Action)}
+	}
+	var meta query.TransactionMeta
+	if err := mapstructure.Decode(def.Meta, &meta); err != nil {
+		return nil
+	}
+	var ops []string
+	seen := make(map[string]bool)
+	for _, op := range meta.Operations {
+		if op.Action == nil {
+			continue
+		}
+		if o := profileOp(*op.Action); !seen[o] {
+			seen[o] = true
+			ops = append(ops, o)
+		}
+	}
+	return ops
+}
+
+// profileOp returns the profiler op type of an action.
+func profileOp(a query.Action) string {
+	switch a {
+	case query.InsertOneAction, query.InsertManyAction:
+		return "insert"
+	case query.UpdateOneAction:
+		return "update"
+	case query.DeleteOneAction:
+		return "remove"
+	}
+	return "query"
+}
+
+// profileQueryName returns the name of the query which performed the
+// operation, rolling the operations of transactions up to their query.
+func profileQueryName(entry bson.M, opQueries map[string][]string, transactions map[string]bool) string {
+	if comment, ok := profileComment(entry); ok && strings.HasPrefix(comment, query.CommentPrefix) {
+		name := strings.TrimPrefix(comment, query.CommentPrefix)
+		if m := profileOperationRe.FindStringSubmatch(name); m != nil && transactions[m[1]] {
+			return m[1]
+		}
+		return name
+	}
+	if op, ok := entry["op"].(string); ok {
+		if names := opQueries[op]; len(names) == 1 {
+			return names[0]
+		}
+	}
+	return unattributedProfile
+}
+
+// profileComment returns the comment of the operation, or the $comment
+// of the filter of updates and deletes, found in the command for recent
+// servers and in the query for older ones.
+func profileComment(entry bson.M) (string, bool) {
+	if comment, ok := entry["comment"].(string); ok {
+		return comment, true
+	}
+	cmd, _ := entry["command"].(bson.M)
+	if comment, ok := cmd["comment"].(string); ok {
+		return comment, true
+	}
+	for _, filter := range []interface{}{cmd["q"], entry["query"]} {
+		if f, ok := filter.(bson.M); ok {
+			if comment, ok := f["$comment"].(string); ok {
+				return comment, true
+			}
+		}
+	}
+	return "", false
+}
+
+// profileLockWait sums the time spent acquiring locks of every resource.
+func profileLockWait(entry bson.M) int64 {
+	locks, ok := entry["locks"].(bson.M)
+	if !ok {
+		return 0
+	}
+	var total int64
+	for _, l := range locks {
+		lock, ok := l.(bson.M)
+		if !ok {
+			continue
+		}
+		acquiring, ok := lock["timeAcquiringMicros"].(bson.M)
+		if !ok {
+			continue
+		}
+		for _, v := range acquiring {
+			total += query.ToInt64(v)
+		}
+	}
+	return total
+}
+
+func mostFrequent(counts map[string]int) string {
+	best, bestCount := "nil", 0
+	for k, n := range counts {
+		if n > bestCount || (n == bestCount && k < best) {
+			best, bestCount = k, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"mongoperf/internal/client/query"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestProfileQueryName(t *testing.T) {
+	opQueries := map[string][]string{
+		"query":  {"find", "t"},
+		"insert": {"insert"},
+		"update": {"update", "t"},
+	}
+	transactions := map[string]bool{"t": true}
+	tests := []struct {
+		name  string
+		entry bson.M
+		want  string
+	}{
+		{
+			name:  "find comment",
+			entry: bson.M{"op": "query", "command": bson.M{"comment": "mongoperf:find"}},
+			want:  "find",
+		},
+		{
+			name:  "legacy comment",
+			entry: bson.M{"op": "query", "comment": "mongoperf:find"},
+			want:  "find",
+		},
+		{
+			name:  "update filter comment",
+			entry: bson.M{"op": "update", "command": bson.M{"q": bson.M{"$comment": "mongoperf:update"}}},
+			want:  "update",
+		},
+		{
+			name:  "legacy remove filter comment",
+			entry: bson.M{"op": "remove", "query": bson.M{"$comment": "mongoperf:delete"}},
+			want:  "delete",
+		},
+		{
+			name:  "transaction operation",
+			entry: bson.M{"op": "update", "command": bson.M{"q": bson.M{"$comment": "mongoperf:t[1]"}}},
+			want:  "t",
+		},
+		{
+			name:  "indexed name of another query",
+			entry: bson.M{"op": "query", "command": bson.M{"comment": "mongoperf:find[1]"}},
+			want:  "find[1]",
+		},
+		{
+			name:  "comment preferred to the type",
+			entry: bson.M{"op": "insert", "command": bson.M{"comment": "mongoperf:find"}},
+			want:  "find",
+		},
+		{
+			name:  "single query of the type",
+			entry: bson.M{"op": "insert"},
+			want:  "insert",
+		},
+		{
+			name:  "several queries of the type",
+			entry: bson.M{"op": "update"},
+			want:  unattributedProfile,
+		},
+		{
+			name:  "foreign comment",
+			entry: bson.M{"op": "query", "command": bson.M{"comment": "dashboard"}},
+			want:  unattributedProfile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileQueryName(tt.entry, opQueries, transactions); got != tt.want {
+				t.Errorf("profileQueryName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileOps(t *testing.T) {
+	tests := []struct {
+		name string
+		def  query.Definition
+		want []string
+	}{
+		{
+			name: "query",
+			def:  query.Definition{Action: action(query.FindAction)},
+			want: []string{"query"},
+		},
+		{
+			name: "transaction",
+			def: query.Definition{Action: action(query.TransactionAction), Meta: map[string]interface{}{
+				"Operations": []interface{}{
+					map[string]interface{}{"Action": "InsertOne"},
+					map[string]interface{}{"Action": "UpdateOneAction"},
+					map[string]interface{}{"Action": "InsertMany"},
+				},
+			}},
+			want: []string{"insert", "update"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileOps(tt.def); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("profileOps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func action(a query.Action) *query.Action {
+	return &a
+}
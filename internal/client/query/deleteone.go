@@ -0,0 +1,44 @@
+package query
+
+import (
+	"context"
+
+	"github.com/mitchellh/mapstructure"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeleteOneMeta .
+type DeleteOneMeta struct {
+	Filter  map[string]interface{} `default:"{}" doc:"Filter selecting the document to delete."`
+	Options *options.DeleteOptions `doc:"Driver delete options."`
+}
+
+// DeleteOneQuery .
+type DeleteOneQuery struct {
+	config *Definition
+	meta   *DeleteOneMeta
+}
+
+// Run implements the Querier interface.
+func (q *DeleteOneQuery) Run(ctx context.Context, col *mongo.Collection) *Result {
+	result := NewQueryResult(q.config)
+	deleteResult, err := col.DeleteOne(ctx, q.meta.Filter, q.meta.Options)
+	if err != nil {
+		return result.WithError(err)
+	}
+	return result.WithResult(int(deleteResult.DeletedCount))
+}
+
+// NewDeleteOneQuery .
+func NewDeleteOneQuery(config *Definition) (Querier, error) {
+	var meta DeleteOneMeta
+	if err := mapstructure.Decode(config.Meta, &meta); err != nil {
+		return nil, err
+	}
+	meta.Filter = commentFilter(meta.Filter, config)
+	if meta.Options == nil {
+		meta.Options = options.Delete()
+	}
+	return &DeleteOneQuery{config: config, meta: &meta}, nil
+}
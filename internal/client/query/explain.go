@@ -0,0 +1,118 @@
+package query
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Explainer is implemented by queriers which can report
+// the query plan chosen by the server.
+type Explainer interface {
+	Explain(context.Context, *mongo.Collection) (*ExplainResult, error)
+}
+
+// ExplainResult holds the interesting parts of an explain
+// output using the executionStats verbosity.
+type ExplainResult struct {
+	Definition *Definition
+
+	Stages              []string
+	IndexName           string
+	KeysExamined        int64
+	DocsExamined        int64
+	NReturned           int64
+	ExecutionTimeMillis int64
+}
+
+// Stage returns the winning plan stages, from outermost to innermost.
+func (r *ExplainResult) Stage() string {
+	return strings.Join(r.Stages, " > ")
+}
+
+// IsCollScan returns whether the winning plan scans the whole collection.
+func (r *ExplainResult) IsCollScan() bool {
+	for _, s := range r.Stages {
+		if s == "COLLSCAN" {
+			return true
+		}
+	}
+	return false
+}
+
+// explain runs the explain command for cmd using the executionStats verbosity.
+func explain(ctx context.Context, col *mongo.Collection, def *Definition, cmd bson.D) (*ExplainResult, error) {
+	explainCmd := bson.D{
+		{Key: "explain", Value: cmd},
+		{Key: "verbosity", Value: "executionStats"},
+	}
+	var out bson.M
+	if err := col.Database().RunCommand(ctx, explainCmd).Decode(&out); err != nil {
+		return nil, err
+	}
+	result := &ExplainResult{Definition: def}
+	if planner, ok := out["queryPlanner"].(bson.M); ok {
+		if plan, ok := planner["winningPlan"].(bson.M); ok {
+			walkPlan(result, plan)
+		}
+	}
+	if stats, ok := out["executionStats"].(bson.M); ok {
+		result.KeysExamined = ToInt64(stats["totalKeysExamined"])
+		result.DocsExamined = ToInt64(stats["totalDocsExamined"])
+		result.NReturned = ToInt64(stats["nReturned"])
+		result.ExecutionTimeMillis = ToInt64(stats["executionTimeMillis"])
+	}
+	return result, nil
+}
+
+// walkPlan follows the first input stage of every plan stage,
+// recording stage names and the first index used.
+func walkPlan(r *ExplainResult, plan bson.M) {
+	for plan != nil {
+		if stage, ok := plan["stage"].(string); ok {
+			r.Stages = append(r.Stages, stage)
+		}
+		if name, ok := plan["indexName"].(string); ok && r.IndexName == "" {
+			r.IndexName = name
+		}
+		if next, ok := plan["inputStage"].(bson.M); ok {
+			plan = next
+			continue
+		}
+		if inputs, ok := plan["inputStages"].(bson.A); ok && len(inputs) > 0 {
+			if next, ok := inputs[0].(bson.M); ok {
+				plan = next
+				continue
+			}
+		}
+		plan = nil
+	}
+}
+
+// findCommand builds a find command from the filter and options shared
+// by the Find and FindOne actions.
+func findCommand(col *mongo.Collection, filter map[string]interface{}, projection, sort, hint interface{}, skip, limit *int64) bson.D {
+	cmd := bson.D{{Key: "find", Value: col.Name()}}
+	if filter == nil {
+		filter = map[string]interface{}{}
+	}
+	cmd = append(cmd, bson.E{Key: "filter", Value: filter})
+	if projection != nil {
+		cmd = append(cmd, bson.E{Key: "projection", Value: projection})
+	}
+	if sort != nil {
+		cmd = append(cmd, bson.E{Key: "sort", Value: sort})
+	}
+	if hint != nil {
+		cmd = append(cmd, bson.E{Key: "hint", Value: hint})
+	}
+	if skip != nil {
+		cmd = append(cmd, bson.E{Key: "skip", Value: *skip})
+	}
+	if limit != nil {
+		cmd = append(cmd, bson.E{Key: "limit", Value: *limit})
+	}
+	return cmd
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestWalkPlan(t *testing.T) {
+	tests := []struct {
+		name         string
+		plan         bson.M
+		wantStages   []string
+		wantIndex    string
+		wantCollScan bool
+	}{
+		{
+			name:         "collection scan",
+			plan:         bson.M{"stage": "COLLSCAN"},
+			wantStages:   []string{"COLLSCAN"},
+			wantCollScan: true,
+		},
+		{
+			name: "nested inputStage",
+			plan: bson.M{"stage": "PROJECTION", "inputStage": bson.M{
+				"stage": "FETCH", "inputStage": bson.M{"stage": "IXSCAN", "indexName": "name_1"},
+			}},
+			wantStages: []string{"PROJECTION", "FETCH", "IXSCAN"},
+			wantIndex:  "name_1",
+		},
+		{
+			name: "first of inputStages",
+			plan: bson.M{"stage": "SORT_MERGE", "inputStages": bson.A{
+				bson.M{"stage": "IXSCAN", "indexName": "a_1", "inputStage": bson.M{"stage": "COLLSCAN"}},
+				bson.M{"stage": "IXSCAN", "indexName": "b_1"},
+			}},
+			wantStages:   []string{"SORT_MERGE", "IXSCAN", "COLLSCAN"},
+			wantIndex:    "a_1",
+			wantCollScan: true,
+		},
+		{
+			name: "first index kept",
+			plan: bson.M{"stage": "FETCH", "indexName": "outer_1", "inputStage": bson.M{
+				"stage": "IXSCAN", "indexName": "inner_1",
+			}},
+			wantStages: []string{"FETCH", "IXSCAN"},
+			wantIndex:  "outer_1",
+		},
+		{
+			name:       "empty inputStages",
+			plan:       bson.M{"stage": "EOF", "inputStages": bson.A{}},
+			wantStages: []string{"EOF"},
+		},
+		{
+			name:         "missing stage",
+			plan:         bson.M{"inputStage": bson.M{"stage": "COLLSCAN"}},
+			wantStages:   []string{"COLLSCAN"},
+			wantCollScan: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r ExplainResult
+			walkPlan(&r, tt.plan)
+			if !reflect.DeepEqual(r.Stages, tt.wantStages) || r.IndexName != tt.wantIndex || r.IsCollScan() != tt.wantCollScan {
+				t.Errorf("got stages %v, index %q and collscan %v, want %v, %q and %v",
+					r.Stages, r.IndexName, r.IsCollScan(), tt.wantStages, tt.wantIndex, tt.wantCollScan)
+			}
+		})
+	}
+}
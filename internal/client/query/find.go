@@ -10,8 +10,8 @@ import (
 
 // FindMeta .
 type FindMeta struct {
-	Filter  map[string]interface{}
-	Options *options.FindOptions
+	Filter  map[string]interface{} `default:"{}" doc:"Filter selecting the documents to return."`
+	Options *options.FindOptions   `doc:"Driver find options. Comment defaults to mongoperf:<query name>."`
 }
 
 // FindQuery .
@@ -44,6 +44,13 @@ func (q *FindQuery) Run(ctx context.Context, col *mongo.Collection) *Result {
 	return result.WithResult(len(results))
 }
 
+// Explain implements the Explainer interface.
+func (q *FindQuery) Explain(ctx context.Context, col *mongo.Collection) (*ExplainResult, error) {
+	o := q.meta.Options
+	cmd := findCommand(col, q.meta.Filter, o.Projection, o.Sort, o.Hint, o.Skip, o.Limit)
+	return explain(ctx, col, q.config, cmd)
+}
+
 // NewFindQuery .
 func NewFindQuery(config *Definition) (Querier, error) {
 	var meta FindMeta
@@ -53,5 +60,8 @@ func NewFindQuery(config *Definition) (Querier, error) {
 	if meta.Options == nil {
 		meta.Options = options.Find()
 	}
+	if meta.Options.Comment == nil {
+		meta.Options.SetComment(Comment(config))
+	}
 	return &FindQuery{config: config, meta: &meta}, nil
 }
@@ -10,8 +10,8 @@ import (
 
 // FindOneMeta .
 type FindOneMeta struct {
-	Filter  map[string]interface{}
-	Options *options.FindOneOptions
+	Filter  map[string]interface{}  `default:"{}" doc:"Filter selecting the document to return."`
+	Options *options.FindOneOptions `doc:"Driver find options. Comment defaults to mongoperf:<query name>."`
 }
 
 // FindOneQuery .
@@ -33,6 +33,14 @@ func (q *FindOneQuery) Run(ctx context.Context, col *mongo.Collection) *Result {
 	return result.WithResult(1)
 }
 
+// Explain implements the Explainer interface.
+func (q *FindOneQuery) Explain(ctx context.Context, col *mongo.Collection) (*ExplainResult, error) {
+	o := q.meta.Options
+	limit := int64(1)
+	cmd := findCommand(col, q.meta.Filter, o.Projection, o.Sort, o.Hint, o.Skip, &limit)
+	return explain(ctx, col, q.config, cmd)
+}
+
 // NewFindOneQuery .
 func NewFindOneQuery(config *Definition) (Querier, error) {
 	var meta FindOneMeta
@@ -42,5 +50,8 @@ func NewFindOneQuery(config *Definition) (Querier, error) {
 	if meta.Options == nil {
 		meta.Options = options.FindOne()
 	}
+	if meta.Options.Comment == nil {
+		meta.Options.SetComment(Comment(config))
+	}
 	return &FindOneQuery{config: config, meta: &meta}, nil
 }
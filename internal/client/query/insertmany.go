@@ -11,8 +11,8 @@ import (
 
 // InsertManyMeta .
 type InsertManyMeta struct {
-	Data    []interface{}
-	Options *options.InsertManyOptions
+	Data    []interface{}              `required:"true" doc:"Documents to insert."`
+	Options *options.InsertManyOptions `doc:"Driver insert options."`
 }
 
 // InsertManyQuery .
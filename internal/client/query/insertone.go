@@ -11,8 +11,8 @@ import (
 
 // InsertOneMeta .
 type InsertOneMeta struct {
-	Data    map[string]interface{}
-	Options *options.InsertOneOptions
+	Data    map[string]interface{}    `required:"true" doc:"Document to insert."`
+	Options *options.InsertOneOptions `doc:"Driver insert options."`
 }
 
 // InsertOneQuery .
@@ -2,17 +2,57 @@ package query
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // Definition .
 type Definition struct {
-	Name   *string                `yaml:"Name"`
-	Action *Action                `yaml:"Action"`
-	Meta   map[string]interface{} `yaml:"Meta"`
+	Name    *string                `yaml:"Name" json:"Name" required:"true" doc:"Identifier of the query."`
+	Action  *Action                `yaml:"Action" json:"Action" required:"true" doc:"Action performed against the collection."`
+	Meta    map[string]interface{} `yaml:"Meta" json:"Meta" required:"true" doc:"Attributes specific to the Action."`
+	Explain *bool                  `yaml:"Explain,omitempty" json:"Explain,omitempty" doc:"Explain the query once after the run. Only Find and FindOneAction support it."`
+
+	Thresholds *Thresholds `yaml:"Thresholds,omitempty" json:"Thresholds,omitempty" doc:"Limits the results of the query must respect."`
+}
+
+// Thresholds declares the limits a query must respect
+// for its results to be considered successful.
+type Thresholds struct {
+	MaxErrors    *int           `yaml:"MaxErrors,omitempty" json:"MaxErrors,omitempty" minimum:"0" doc:"Maximum number of failed queries."`
+	MaxErrorRate *float64       `yaml:"MaxErrorRate,omitempty" json:"MaxErrorRate,omitempty" minimum:"0" maximum:"1" doc:"Maximum fraction of failed queries."`
+	MinOpsPerSec *float64       `yaml:"MinOpsPerSec,omitempty" json:"MinOpsPerSec,omitempty" minimum:"0" doc:"Minimum number of queries per second."`
+	MaxP95       *time.Duration `yaml:"MaxP95,omitempty" json:"MaxP95,omitempty" doc:"Maximum 95th percentile latency."`
+	MaxP99       *time.Duration `yaml:"MaxP99,omitempty" json:"MaxP99,omitempty" doc:"Maximum 99th percentile latency."`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaller interface.
+func (t *Thresholds) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type T Thresholds
+	newThresholds := (*T)(t)
+	if err := unmarshal(&newThresholds); err != nil {
+		return err
+	}
+	if t.MaxErrors != nil && *t.MaxErrors < 0 {
+		return fmt.Errorf("MaxErrors must be greater than or equal to 0")
+	}
+	if r := t.MaxErrorRate; r != nil && (*r < 0 || *r > 1) {
+		return fmt.Errorf("MaxErrorRate must be between 0 and 1")
+	}
+	if t.MinOpsPerSec != nil && *t.MinOpsPerSec < 0 {
+		return fmt.Errorf("MinOpsPerSec must be greater than or equal to 0")
+	}
+	if t.MaxP95 != nil && *t.MaxP95 <= 0 {
+		return fmt.Errorf("MaxP95 must be greater than 0")
+	}
+	if t.MaxP99 != nil && *t.MaxP99 <= 0 {
+		return fmt.Errorf("MaxP99 must be greater than 0")
+	}
+	return nil
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaller interface.
@@ -28,6 +68,42 @@ func (c *Definition) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// MarshalJSON implements the json.Marshaler interface.
+func (c *Definition) MarshalJSON() ([]byte, error) {
+	type C Definition
+	newConfig := C(*c)
+	if c.Meta != nil {
+		newConfig.Meta = JSONValue(c.Meta).(map[string]interface{})
+	}
+	return json.Marshal(newConfig)
+}
+
+// JSONValue converts the maps decoded from YAML, whose keys
+// are interfaces, to maps which can be encoded as JSON.
+func JSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = JSONValue(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = JSONValue(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = JSONValue(val)
+		}
+		return s
+	}
+	return v
+}
+
 // Action .
 type Action string
 
@@ -41,22 +117,59 @@ func (a *Action) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if a == nil {
 		return fmt.Errorf("Action must not be empty")
 	}
-	switch *a {
-	case InsertOneAction, InsertManyAction, UpdateOneAction, FindOneAction, FindAction:
-		return nil
+	for _, action := range Actions {
+		if *a == action {
+			return nil
+		}
 	}
 	return fmt.Errorf("Action not supported")
 }
 
 // Action enum .
 const (
-	InsertOneAction  Action = "InsertOne"
-	InsertManyAction        = "InsertMany"
-	UpdateOneAction         = "UpdateOneAction"
-	FindOneAction           = "FindOneAction"
-	FindAction              = "Find"
+	InsertOneAction   Action = "InsertOne"
+	InsertManyAction         = "InsertMany"
+	UpdateOneAction          = "UpdateOneAction"
+	FindOneAction            = "FindOneAction"
+	FindAction               = "Find"
+	DeleteOneAction          = "DeleteOne"
+	TransactionAction        = "Transaction"
 )
 
+// Actions lists the supported actions.
+var Actions = []Action{
+	InsertOneAction,
+	InsertManyAction,
+	UpdateOneAction,
+	FindOneAction,
+	FindAction,
+	DeleteOneAction,
+	TransactionAction,
+}
+
+// CommentPrefix prefixes the comment attached to operations
+// which support it, followed by the query name.
+const CommentPrefix = "mongoperf:"
+
+// Comment returns the comment used to tag the operations of a query.
+func Comment(def *Definition) string {
+	return CommentPrefix + *def.Name
+}
+
+// commentFilter returns a copy of the filter tagged with the comment of
+// the query using the $comment operator, as the driver does not support
+// comments on writes. A $comment already in the filter is kept.
+func commentFilter(filter map[string]interface{}, def *Definition) map[string]interface{} {
+	tagged := make(map[string]interface{}, len(filter)+1)
+	for k, v := range filter {
+		tagged[k] = v
+	}
+	if _, ok := tagged["$comment"]; !ok {
+		tagged["$comment"] = Comment(def)
+	}
+	return tagged
+}
+
 // Querier .
 type Querier interface {
 	Run(context.Context, *mongo.Collection) *Result
@@ -90,20 +203,90 @@ func NewQuerier(config *Definition) (Querier, error) {
 		querier, err = NewFindOneQuery(config)
 	case FindAction:
 		querier, err = NewFindQuery(config)
+	case DeleteOneAction:
+		querier, err = NewDeleteOneQuery(config)
+	case TransactionAction:
+		querier, err = NewTransactionQuery(config)
 	}
 	return querier, err
 }
 
+// NewMeta returns a pointer to the Meta type of the action.
+func NewMeta(a Action) (interface{}, error) {
+	switch a {
+	case InsertOneAction:
+		return &InsertOneMeta{}, nil
+	case InsertManyAction:
+		return &InsertManyMeta{}, nil
+	case UpdateOneAction:
+		return &UpdateOneMeta{}, nil
+	case FindOneAction:
+		return &FindOneMeta{}, nil
+	case FindAction:
+		return &FindMeta{}, nil
+	case DeleteOneAction:
+		return &DeleteOneMeta{}, nil
+	case TransactionAction:
+		return &TransactionMeta{}, nil
+	}
+	return nil, fmt.Errorf("action not supported")
+}
+
+// CheckMeta decodes the Meta of the definition into the Meta
+// type of its action, failing on keys which the type does not declare.
+func CheckMeta(config *Definition) error {
+	if config.Action == nil {
+		return fmt.Errorf("config.Action is nil")
+	}
+	meta, err := NewMeta(*config.Action)
+	if err != nil {
+		return err
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused: true,
+		Result:      meta,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(config.Meta); err != nil {
+		return err
+	}
+	if t, ok := meta.(*TransactionMeta); ok {
+		for i, op := range t.Operations {
+			if err := CheckMeta(&Definition{Action: op.Action, Meta: op.Meta}); err != nil {
+				return fmt.Errorf("Operations[%d]: %v", i, err)
+			}
+		}
+	}
+	return nil
+}
+
 // Result .
 type Result struct {
 	Definition *Definition
+	WorkerID   int
+	Iteration  int
 
+	// Scheduled is when the query was due to be sent, when the
+	// scenario has a Rate. It is zero otherwise.
+	Scheduled   time.Time
 	Start       time.Time
 	End         time.Time
 	TotalChange int
 	Error       error
 }
 
+// Latency returns the time elapsed since the query was due to be sent,
+// including the time it waited for a worker, or since it was sent
+// when it was not scheduled.
+func (r *Result) Latency() time.Duration {
+	if r.Scheduled.IsZero() {
+		return r.End.Sub(r.Start)
+	}
+	return r.End.Sub(r.Scheduled)
+}
+
 // NewQueryResult .
 func NewQueryResult(q *Definition) *Result {
 	return &Result{
@@ -135,3 +318,21 @@ func (r *Result) setEnd() {
 func Int(i int) *int {
 	return &i
 }
+
+// String .
+func String(s string) *string {
+	return &s
+}
+
+// ToInt64 returns the value of a BSON number, 0 if v is not a number.
+func ToInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommentFilter(t *testing.T) {
+	def := &Definition{Name: String("update")}
+	tests := []struct {
+		name   string
+		filter map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{
+			name: "nil filter",
+			want: map[string]interface{}{"$comment": "mongoperf:update"},
+		},
+		{
+			name:   "filter",
+			filter: map[string]interface{}{"_id": 1},
+			want:   map[string]interface{}{"_id": 1, "$comment": "mongoperf:update"},
+		},
+		{
+			name:   "comment kept",
+			filter: map[string]interface{}{"_id": 1, "$comment": "mine"},
+			want:   map[string]interface{}{"_id": 1, "$comment": "mine"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before map[string]interface{}
+			if tt.filter != nil {
+				before = make(map[string]interface{})
+				for k, v := range tt.filter {
+					before[k] = v
+				}
+			}
+			got := commentFilter(tt.filter, def)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commentFilter() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.filter, before) {
+				t.Errorf("commentFilter() modified the filter: %v", tt.filter)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TransactionOperation is an operation run inside a transaction.
+type TransactionOperation struct {
+	Action *Action                `required:"true" doc:"Action of the operation, which cannot be Transaction."`
+	Meta   map[string]interface{} `required:"true" doc:"Meta of the action."`
+}
+
+// TransactionMeta .
+type TransactionMeta struct {
+	Operations []TransactionOperation      `required:"true" doc:"Operations run in order inside the transaction."`
+	Options    *options.TransactionOptions `doc:"Driver transaction options."`
+}
+
+// TransactionQuery .
+type TransactionQuery struct {
+	config     *Definition
+	meta       *TransactionMeta
+	operations []Querier
+}
+
+// Run implements the Querier interface.
+//
+// The operations are run in order inside a transaction, which is
+// retried by the driver on transient errors. The total change is
+// the sum of the changes of the operations of the committed attempt.
+func (q *TransactionQuery) Run(ctx context.Context, col *mongo.Collection) *Result {
+	result := NewQueryResult(q.config)
+	sess, err := col.Database().Client().StartSession()
+	if err != nil {
+		return result.WithError(err)
+	}
+	defer sess.EndSession(ctx)
+
+	total, err := sess.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		total := 0
+		for _, op := range q.operations {
+			r := op.Run(sessCtx, col)
+			if r.Error != nil {
+				return nil, r.Error
+			}
+			total += r.TotalChange
+		}
+		return total, nil
+	}, q.meta.Options)
+	if err != nil {
+		return result.WithError(err)
+	}
+	return result.WithResult(total.(int))
+}
+
+// NewTransactionQuery .
+func NewTransactionQuery(config *Definition) (Querier, error) {
+	var meta TransactionMeta
+	if err := mapstructure.Decode(config.Meta, &meta); err != nil {
+		return nil, err
+	}
+	if len(meta.Operations) == 0 {
+		return nil, fmt.Errorf("Operations is empty")
+	}
+	if meta.Options == nil {
+		meta.Options = options.Transaction()
+	}
+	var operations []Querier
+	for i, op := range meta.Operations {
+		if op.Action == nil {
+			return nil, fmt.Errorf("Operations[%d]: Action must not be empty", i)
+		}
+		if *op.Action == TransactionAction {
+			return nil, fmt.Errorf("Operations[%d]: transactions cannot be nested", i)
+		}
+		def := &Definition{
+			Name:   String(fmt.Sprintf("%v[%d]", *config.Name, i)),
+			Action: op.Action,
+			Meta:   op.Meta,
+		}
+		querier, err := NewQuerier(def)
+		if err != nil {
+			return nil, fmt.Errorf("Operations[%d]: %v", i, err)
+		}
+		operations = append(operations, querier)
+	}
+	return &TransactionQuery{config: config, meta: &meta, operations: operations}, nil
+}
@@ -11,9 +11,9 @@ import (
 
 // UpdateOneMeta .
 type UpdateOneMeta struct {
-	Data    map[string]interface{}
-	Filter  map[string]interface{}
-	Options *options.UpdateOptions
+	Data    map[string]interface{} `required:"true" doc:"Document containing update operators."`
+	Filter  map[string]interface{} `default:"{}" doc:"Filter selecting the document to update."`
+	Options *options.UpdateOptions `doc:"Driver update options."`
 }
 
 // UpdateOneQuery .
@@ -41,6 +41,7 @@ func NewUpdateOneQuery(config *Definition) (Querier, error) {
 	if len(meta.Data) == 0 {
 		return nil, fmt.Errorf("Data is empty")
 	}
+	meta.Filter = commentFilter(meta.Filter, config)
 	if meta.Options == nil {
 		meta.Options = options.Update()
 	}
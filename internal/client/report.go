@@ -1,9 +1,15 @@
 package client
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"mongoperf/internal/client/query"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"text/template"
 	"time"
@@ -32,6 +38,48 @@ var (
 {{ block "query" . }}{{ end }}
 {{- end }}
 {{- end }}
+{{- with .Breaches }}
+---------------------------------------
+  Threshold Breaches
+---------------------------------------
+{{ block "breaches" . }}{{ end }}
+{{- end }}
+{{- with .Workers }}
+---------------------------------------
+  Workers
+---------------------------------------
+{{ range . -}}
+{{ block "worker" . }}{{ end }}
+{{- end }}
+{{- end }}
+{{- with .Pool }}
+---------------------------------------
+  Connection Pool
+---------------------------------------
+{{ block "pool" . }}{{ end }}
+{{- end }}
+{{- with .Server }}
+---------------------------------------
+  Server Stats
+---------------------------------------
+{{ block "server" . }}{{ end }}
+{{- end }}
+{{- with .Explains }}
+---------------------------------------
+  Explain
+---------------------------------------
+{{ range . -}}
+{{ block "explain" . }}{{ end }}
+{{- end }}
+{{- end }}
+{{- with .Profiles }}
+---------------------------------------
+  Profiler
+---------------------------------------
+{{ range . -}}
+{{ block "profile" . }}{{ end }}
+{{- end }}
+{{- end }}
 =======================================
 `
 
@@ -42,6 +90,12 @@ var (
     Collection: {{ .Collection }}
     Parallel:   {{ .Parallel }}
     Repeat:     {{ .Repeat }}
+{{- with .Scenario }}{{ with .Rate }}
+    Rate:       {{ . }}/s
+{{- end }}{{ end }}
+{{- with .Scenario }}{{ with .Params }}
+    Params:     {{ range $k, $v := . }}{{ $k }}={{ $v }} {{ end }}
+{{- end }}{{ end }}
 {{ end }}
 `
 
@@ -55,84 +109,336 @@ var (
     ChangeAvg:         {{ .ChangeAvg }}
     EPS:               {{ .EPS }}
     TotalTime:         {{ .TotalTime }}
+    P50:               {{ .P50 }}
+    P95:               {{ .P95 }}
+    P99:               {{ .P99 }}
+    Max:               {{ .Max }}
     Successful:        {{ .Successful }}
     ErrorCount:        {{ .ErrorCount }}
     LastError:         {{ .LastError }}
 {{ end }}
+`
+
+	breachesBlock = `
+{{ define "breaches" }}
+{{ range . }}  > {{ .Query }}: {{ .Message }}
+{{ end }}
+{{- end }}
+`
+
+	workerBlock = `
+{{ define "worker" }}
+  > Worker:            {{ .ID }}
+    QueryCount:        {{ .QueryCount }}
+    Share:             {{ printf "%.2f" .Share }}%
+    BusyTime:          {{ .BusyTime }}
+    P50:               {{ .P50 }}
+    P95:               {{ .P95 }}
+    P99:               {{ .P99 }}
+    Max:               {{ .Max }}
+    ErrorCount:        {{ .ErrorCount }}
+{{ end }}
+`
+
+	poolBlock = `
+{{ define "pool" }}
+    ConnectionsCreated: {{ .ConnectionsCreated }}
+    ConnectionsClosed:  {{ .ConnectionsClosed }}
+    CheckedOut:         {{ .CheckedOut }}
+    CheckedIn:          {{ .CheckedIn }}
+    CheckOutFailed:     {{ .CheckOutFailed }}
+    CheckOutWaitAvg:    {{ .CheckOutWaitAvg }}
+    CheckOutWaitMax:    {{ .CheckOutWaitMax }}
+    PoolCleared:        {{ .PoolCleared }}
+{{ end }}
+`
+
+	serverBlock = `
+{{ define "server" }}
+{{ range . }}    {{ printf "%-50s" .Name }} {{ printf "%15d" .Before }} -> {{ printf "%15d" .After }} ({{ printf "%+d" .Delta }})
+{{ end }}
+{{- end }}
+`
+
+	explainBlock = `
+{{ define "explain" }}
+  > Name:              {{ .Name }}
+    Action:            {{ .Action }}
+    Stage:             {{ .Stage }}
+    Index:             {{ .IndexName }}
+    KeysExamined:      {{ .KeysExamined }}
+    DocsExamined:      {{ .DocsExamined }}
+    NReturned:         {{ .NReturned }}
+    ExecutionTimeMS:   {{ .ExecutionTimeMillis }}
+    Warning:           {{ .Warning }}
+{{ end }}
+`
+
+	profileBlock = `
+{{ define "profile" }}
+  > Name:              {{ .Name }}
+    Ops:               {{ .Ops }}
+    TotalMillis:       {{ .TotalMillis }}
+    AvgMillis:         {{ printf "%.2f" .AvgMillis }}
+    MaxMillis:         {{ .MaxMillis }}
+    LockWaitMicros:    {{ .LockWaitMicros }}
+    PlanSummary:       {{ .PlanSummary }}
+{{ end }}
 `
 )
 
+// ReportSchemaVersion is the version of the JSON report schema.
+// It must be incremented on every incompatible change of the Report type.
+const ReportSchemaVersion = 1
+
+// Report kinds identify the type of JSON reports .
+const (
+	RunReportKind     = "run"
+	SweepReportKind   = "sweep"
+	FindMaxReportKind = "find-max"
+)
+
+// Report formats .
+const (
+	TextFormat     = "text"
+	JSONFormat     = "json"
+	CSVFormat      = "csv"
+	MarkdownFormat = "markdown"
+	HTMLFormat     = "html"
+	JUnitFormat    = "junit"
+)
+
+// ReportFormats lists the supported report formats.
+var ReportFormats = []string{TextFormat, JSONFormat, CSVFormat, MarkdownFormat, HTMLFormat, JUnitFormat}
+
+// reportQueryColumns are the columns of the tabular report formats.
+var reportQueryColumns = []string{
+	"Name", "Action", "QueryCount", "QueryAvg", "ChangeCount", "ChangeAvg", "EPS", "TotalTime",
+	"P50", "P95", "P99", "Max", "Successful", "ErrorCount", "LastError",
+}
+
 // Report .
 type Report struct {
-	Version    string
-	URI        string
-	Database   string
-	Collection string
-	Parallel   int
-	Repeat     int
-	Results    []*ReportQueryResult
+	Kind          string    `json:"kind"`
+	SchemaVersion int       `json:"schemaVersion"`
+	GeneratedAt   time.Time `json:"generatedAt"`
+	Scenario      *Scenario `json:"scenario"`
+
+	Start   time.Time     `json:"start"`
+	End     time.Time     `json:"end"`
+	Elapsed time.Duration `json:"elapsed"`
+
+	TimelineInterval time.Duration `json:"timelineInterval"`
+
+	Version    string                `json:"version"`
+	URI        string                `json:"uri"`
+	Database   string                `json:"database"`
+	Collection string                `json:"collection"`
+	Parallel   int                   `json:"parallel"`
+	Repeat     int                   `json:"repeat"`
+	Results    []*ReportQueryResult  `json:"results"`
+	Total      *ReportQueryResult    `json:"total"`
+	Breaches   []ThresholdBreach     `json:"breaches,omitempty"`
+	Workers    []*ReportWorkerResult `json:"workers,omitempty"`
+	Pool       *PoolStats            `json:"pool,omitempty"`
+	Server     []ServerStatDelta     `json:"server,omitempty"`
+	Explains   []*ReportExplain      `json:"explains,omitempty"`
+	Profiles   []*ReportProfile      `json:"profiles,omitempty"`
+}
+
+// RedactURI replaces the credentials of the connection string,
+// so that reports can be shared.
+func RedactURI(uri string) string {
+	i := strings.Index(uri, "://")
+	if i < 0 {
+		return uri
+	}
+	rest := uri[i+3:]
+	end := strings.IndexAny(rest, "/?")
+	if end < 0 {
+		end = len(rest)
+	}
+	at := strings.LastIndex(rest[:end], "@")
+	if at < 0 {
+		return uri
+	}
+	return uri[:i+3] + "xxxxx@" + rest[at+1:]
 }
 
 // NewReport .
-func NewReport(version, uri string, s *Scenario, results map[string]*ReportAggregator) *Report {
+func NewReport(version, uri string, s *Scenario, results *ScenarioResult) *Report {
 	r := &Report{
+		Kind:          RunReportKind,
+		SchemaVersion: ReportSchemaVersion,
+		GeneratedAt:   time.Now(),
+		Scenario:      s,
+
+		Start:   results.Start,
+		End:     results.End,
+		Elapsed: results.End.Sub(results.Start),
+
 		Version:    version,
-		URI:        uri,
+		URI:        RedactURI(uri),
 		Database:   *s.Database,
 		Collection: *s.Collection,
 		Parallel:   *s.Parallel,
 		Repeat:     *s.Repeat,
 	}
-	for _, res := range results {
-		err := "nil"
-		success := res.LastError == nil
-		if !success {
-			err = res.LastError.Error()
-		}
+	total := NewReportQuery(nil, *s.Parallel, results.Start)
+	for _, res := range results.Queries {
+		total.Merge(res)
+	}
+	// timelines of long runs have a coarser interval,
+	// the one of the total is shared by every query
+	r.TimelineInterval = total.Timeline.Interval
+	for _, res := range results.Queries {
 		totalTime := time.Duration(int64(res.WorkTotal) / int64(*s.Parallel))
-		queryAvg := time.Duration(0)
-		if res.QueryCount > 0 {
-			queryAvg = time.Duration(int64(totalTime) / int64(res.QueryCount))
-		}
-		changeAvg := time.Duration(0)
-		if res.ChangeCount > 0 {
-			changeAvg = time.Duration(int64(totalTime) / int64(res.ChangeCount))
-		}
-		eps := float64(0)
-		if totalTime > 0 {
-			eps = float64(res.QueryCount) / totalTime.Seconds()
-		}
-		rqr := &ReportQueryResult{
-			Name:        *res.Definition.Name,
-			Action:      string(*res.Definition.Action),
-			QueryCount:  res.QueryCount,
-			QueryAvg:    queryAvg,
-			ChangeCount: res.ChangeCount,
-			ChangeAvg:   changeAvg,
-			EPS:         eps,
-			TotalTime:   totalTime,
-			Successful:  success,
-			ErrorCount:  res.ErrorCount,
-			LastError:   err,
-		}
+		rqr := newReportQueryResult(*res.Definition.Name, string(*res.Definition.Action), res, totalTime)
+		rqr.Thresholds = res.Definition.Thresholds
+		rqr.Timeline = res.Timeline.SlotsAt(r.TimelineInterval)
 		r.Results = append(r.Results, rqr)
 	}
+	r.Total = newReportQueryResult("total", "", total, r.Elapsed)
+	r.Total.Thresholds = s.Thresholds
+	r.Breaches = CheckReportThresholds(r)
+	sort.Slice(r.Results, func(i, j int) bool { return r.Results[i].Name < r.Results[j].Name })
 	return r
 }
 
+// newReportQueryResult computes the report statistics of the aggregated results,
+// using totalTime as the time spent running them.
+func newReportQueryResult(name, action string, res *ReportAggregator, totalTime time.Duration) *ReportQueryResult {
+	err := "nil"
+	success := res.LastError == nil
+	if !success {
+		err = res.LastError.Error()
+	}
+	queryAvg := time.Duration(0)
+	if res.QueryCount > 0 {
+		queryAvg = time.Duration(int64(totalTime) / int64(res.QueryCount))
+	}
+	changeAvg := time.Duration(0)
+	if res.ChangeCount > 0 {
+		changeAvg = time.Duration(int64(totalTime) / int64(res.ChangeCount))
+	}
+	eps := float64(0)
+	if totalTime > 0 {
+		eps = float64(res.QueryCount) / totalTime.Seconds()
+	}
+	return &ReportQueryResult{
+		Name:        name,
+		Action:      action,
+		QueryCount:  res.QueryCount,
+		QueryAvg:    queryAvg,
+		ChangeCount: res.ChangeCount,
+		ChangeAvg:   changeAvg,
+		EPS:         eps,
+		TotalTime:   totalTime,
+		P50:         res.Latency.Percentile(50),
+		P95:         res.Latency.Percentile(95),
+		P99:         res.Latency.Percentile(99),
+		Max:         res.Latency.Max,
+		Successful:  success,
+		ErrorCount:  res.ErrorCount,
+		LastError:   err,
+		Histogram:   res.Latency.Buckets(),
+	}
+}
+
 // ReportQueryResult .
 type ReportQueryResult struct {
-	Name        string
-	Action      string
-	QueryCount  int
-	QueryAvg    time.Duration
-	ChangeCount int
-	ChangeAvg   time.Duration
-	EPS         float64
-	TotalTime   time.Duration
-	Successful  bool
-	ErrorCount  int
-	LastError   string
+	Name        string        `json:"name"`
+	Action      string        `json:"action"`
+	QueryCount  int           `json:"queryCount"`
+	QueryAvg    time.Duration `json:"queryAvg"`
+	ChangeCount int           `json:"changeCount"`
+	ChangeAvg   time.Duration `json:"changeAvg"`
+	EPS         float64       `json:"eps"`
+	TotalTime   time.Duration `json:"totalTime"`
+	P50         time.Duration `json:"p50"`
+	P95         time.Duration `json:"p95"`
+	P99         time.Duration `json:"p99"`
+	Max         time.Duration `json:"max"`
+	Successful  bool          `json:"successful"`
+	ErrorCount  int           `json:"errorCount"`
+	LastError   string        `json:"lastError"`
+
+	Timeline   []TimelineSlot    `json:"timeline,omitempty"`
+	Histogram  []HistogramBucket `json:"histogram,omitempty"`
+	Thresholds *query.Thresholds `json:"thresholds,omitempty"`
+}
+
+// ErrorRate returns the fraction of queries which failed.
+func (r *ReportQueryResult) ErrorRate() float64 {
+	if r.QueryCount == 0 {
+		return 0
+	}
+	return float64(r.ErrorCount) / float64(r.QueryCount)
+}
+
+// Percentile returns the latency below which p percent of the queries completed,
+// computed from the latency histogram.
+func (r *ReportQueryResult) Percentile(p float64) time.Duration {
+	var total int64
+	for _, b := range r.Histogram {
+		total += b.Count
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(p / 100 * float64(total)))
+	var seen int64
+	for _, b := range r.Histogram {
+		seen += b.Count
+		if seen >= rank {
+			if b.UpperBound > r.Max {
+				return r.Max
+			}
+			return b.UpperBound
+		}
+	}
+	return r.Max
+}
+
+// ReportWorkerResult .
+type ReportWorkerResult struct {
+	ID         int           `json:"id"`
+	QueryCount int           `json:"queryCount"`
+	Share      float64       `json:"share"`
+	BusyTime   time.Duration `json:"busyTime"`
+	P50        time.Duration `json:"p50"`
+	P95        time.Duration `json:"p95"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+	ErrorCount int           `json:"errorCount"`
+}
+
+// NewReportWorkers returns the per worker breakdown of a run, ordered by worker id.
+func NewReportWorkers(results *ScenarioResult) []*ReportWorkerResult {
+	total := 0
+	for _, res := range results.Workers {
+		total += res.QueryCount
+	}
+	var workers []*ReportWorkerResult
+	for id, res := range results.Workers {
+		share := float64(0)
+		if total > 0 {
+			share = float64(res.QueryCount) / float64(total) * 100
+		}
+		workers = append(workers, &ReportWorkerResult{
+			ID:         id,
+			QueryCount: res.QueryCount,
+			Share:      share,
+			BusyTime:   res.WorkTotal,
+			P50:        res.Latency.Percentile(50),
+			P95:        res.Latency.Percentile(95),
+			P99:        res.Latency.Percentile(99),
+			Max:        res.Latency.Max,
+			ErrorCount: res.ErrorCount,
+		})
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].ID < workers[j].ID })
+	return workers
 }
 
 // ReportAggregator .
@@ -146,31 +452,106 @@ type ReportAggregator struct {
 	ChangeCount int
 	ErrorCount  int
 	LastError   error
+	Latency     *Histogram
+	// Timeline is nil for the results of a single worker.
+	Timeline *Timeline
 }
 
 // NewReportQuery .
-func NewReportQuery(d *query.Definition, c int) *ReportAggregator {
+func NewReportQuery(d *query.Definition, c int, start time.Time) *ReportAggregator {
 	return &ReportAggregator{
 		Definition:  d,
 		WorkerCount: c,
 		mu:          &sync.Mutex{},
 		WorkTotal:   time.Duration(0),
+		Latency:     NewHistogram(),
+		Timeline:    NewTimeline(start, TimelineInterval),
 	}
 }
 
+// Merge adds the results aggregated by o.
+func (rq *ReportAggregator) Merge(o *ReportAggregator) {
+	rq.mu.Lock()
+	defer rq.mu.Unlock()
+	rq.WorkTotal += o.WorkTotal
+	rq.QueryCount += o.QueryCount
+	rq.ChangeCount += o.ChangeCount
+	rq.ErrorCount += o.ErrorCount
+	if o.LastError != nil {
+		rq.LastError = o.LastError
+	}
+	rq.Latency.Merge(o.Latency)
+	if rq.Timeline != nil && o.Timeline != nil {
+		rq.Timeline.Merge(o.Timeline)
+	}
+}
+
+// NewReportWorker returns a ReportAggregator
+// for the results of a single worker, without timeline.
+func NewReportWorker(start time.Time) *ReportAggregator {
+	rq := NewReportQuery(nil, 1, start)
+	rq.Timeline = nil
+	return rq
+}
+
 // Update .
-func (rq *ReportAggregator) Update(dur time.Duration, changes int, err error) {
+func (rq *ReportAggregator) Update(result *query.Result) {
 	rq.mu.Lock()
 	defer rq.mu.Unlock()
+	dur := result.End.Sub(result.Start)
 	rq.QueryCount++
 	rq.WorkTotal += dur
-	rq.ChangeCount += changes
-	if err != nil {
+	rq.ChangeCount += result.TotalChange
+	rq.Latency.Record(result.Latency())
+	if rq.Timeline != nil {
+		rq.Timeline.Record(result.End, result.Latency(), result.Error)
+	}
+	if result.Error != nil {
 		rq.ErrorCount++
-		rq.LastError = err
+		rq.LastError = result.Error
 	}
 }
 
+// reportFuncs are the functions available to report templates.
+var reportFuncs = template.FuncMap{
+	"ms":               durationMillis,
+	"round":            roundDuration,
+	"percentile":       (*ReportQueryResult).Percentile,
+	"sortByName":       sortByName,
+	"sortByThroughput": sortByThroughput,
+}
+
+// durationMillis returns d in milliseconds.
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// roundDuration rounds d to the provided precision, such as "1ms".
+func roundDuration(d time.Duration, precision string) (time.Duration, error) {
+	p, err := time.ParseDuration(precision)
+	if err != nil {
+		return 0, err
+	}
+	if p <= 0 {
+		return d, nil
+	}
+	return time.Duration((int64(d) + int64(p)/2) / int64(p) * int64(p)), nil
+}
+
+// sortByName returns the results ordered by query name.
+func sortByName(results []*ReportQueryResult) []*ReportQueryResult {
+	sorted := append([]*ReportQueryResult(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// sortByThroughput returns the results ordered by decreasing EPS.
+func sortByThroughput(results []*ReportQueryResult) []*ReportQueryResult {
+	sorted := append([]*ReportQueryResult(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].EPS > sorted[j].EPS })
+	return sorted
+}
+
 func parseTemplates(name string, tmpl ...string) (*template.Template, error) {
 	if len(tmpl) == 0 {
 		return nil, fmt.Errorf("no templates provided")
@@ -178,7 +559,7 @@ func parseTemplates(name string, tmpl ...string) (*template.Template, error) {
 	var t *template.Template
 	for idx, tStr := range tmpl {
 		if t == nil {
-			t = template.New(fmt.Sprintf("%v-%d", name, idx))
+			t = template.New(fmt.Sprintf("%v-%d", name, idx)).Funcs(reportFuncs)
 		}
 		var err error
 		t, err = t.Parse(tStr)
@@ -189,9 +570,110 @@ func parseTemplates(name string, tmpl ...string) (*template.Template, error) {
 	return t, nil
 }
 
+// WriteReport renders the report using the provided format.
+func WriteReport(w io.Writer, r *Report, format string) error {
+	switch format {
+	case TextFormat:
+		return GenerateReport(w, r)
+	case JSONFormat:
+		return GenerateJSONReport(w, r)
+	case CSVFormat:
+		return GenerateCSVReport(w, r)
+	case MarkdownFormat:
+		return GenerateMarkdownReport(w, r)
+	case HTMLFormat:
+		return GenerateHTMLReport(w, r)
+	case JUnitFormat:
+		return GenerateJUnitReport(w, r)
+	}
+	return fmt.Errorf("report format not supported: %v", format)
+}
+
+// GenerateJSONReport .
+func GenerateJSONReport(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// GenerateCSVReport writes one row per query, durations in milliseconds.
+func GenerateCSVReport(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(reportQueryColumns); err != nil {
+		return err
+	}
+	for _, res := range r.Results {
+		if err := cw.Write(reportQueryRow(res, formatMillis)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// GenerateMarkdownReport writes one table row per query.
+func GenerateMarkdownReport(w io.Writer, r *Report) error {
+	separators := make([]string, len(reportQueryColumns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	lines := []string{
+		markdownRow(reportQueryColumns),
+		markdownRow(separators),
+	}
+	for _, res := range r.Results {
+		lines = append(lines, markdownRow(reportQueryRow(res, time.Duration.String)))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+func reportQueryRow(r *ReportQueryResult, dur func(time.Duration) string) []string {
+	return []string{
+		r.Name,
+		r.Action,
+		strconv.Itoa(r.QueryCount),
+		dur(r.QueryAvg),
+		strconv.Itoa(r.ChangeCount),
+		dur(r.ChangeAvg),
+		strconv.FormatFloat(r.EPS, 'f', 2, 64),
+		dur(r.TotalTime),
+		dur(r.P50),
+		dur(r.P95),
+		dur(r.P99),
+		dur(r.Max),
+		strconv.FormatBool(r.Successful),
+		strconv.Itoa(r.ErrorCount),
+		r.LastError,
+	}
+}
+
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		c = strings.Replace(c, "|", "\\|", -1)
+		escaped[i] = strings.Replace(c, "\n", " ", -1)
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+func formatMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// reportBlocks are the named templates used by the text report.
+// They are also available to user supplied templates.
+var reportBlocks = []string{configBlock, queryBlock, breachesBlock, workerBlock, poolBlock, serverBlock, explainBlock, profileBlock}
+
 // GenerateReport .
 func GenerateReport(w io.Writer, r *Report) error {
-	templates := []string{reportTemplate, configBlock, queryBlock}
+	return GenerateTemplateReport(w, r, reportTemplate)
+}
+
+// GenerateTemplateReport executes the provided text/template against the report.
+// The template is parsed after the reportBlocks, so that it can redefine them.
+func GenerateTemplateReport(w io.Writer, r *Report, tmpl string) error {
+	templates := append(append([]string{}, reportBlocks...), tmpl)
 	t, err := parseTemplates("report-template", templates...)
 	if err != nil {
 		return err
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlReportTemplate renders a self-contained HTML page.
+// Charts are drawn client side from the report data embedded as JSON,
+// without any external resource.
+var htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>mongoperf report - {{ .Database }}.{{ .Collection }}</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+  h1 { font-size: 1.6em; margin-bottom: 0; }
+  h2 { font-size: 1.2em; margin-top: 2em; border-bottom: 1px solid #ddd; padding-bottom: .3em; }
+  .meta { color: #666; margin-top: .3em; }
+  table { border-collapse: collapse; font-size: .9em; }
+  th, td { border: 1px solid #ddd; padding: .3em .6em; text-align: right; }
+  th { background: #f5f5f5; }
+  td.text, th.text { text-align: left; }
+  td.error { color: #b00020; }
+  .chart { margin: 1em 0; }
+  .charts { display: flex; flex-wrap: wrap; gap: 1em; }
+  canvas { border: 1px solid #eee; }
+  .legend span { display: inline-block; margin-right: 1em; font-size: .85em; }
+  .legend i { display: inline-block; width: .8em; height: .8em; margin-right: .3em; }
+</style>
+</head>
+<body>
+<h1>Scenario Report</h1>
+<div class="meta">Generated by mongoperf {{ .Version }} on {{ .GeneratedAt.Format "2006-01-02 15:04:05 MST" }}</div>
+
+<h2>Config</h2>
+<table>
+  <tr><th class="text">URI</th><td class="text">{{ .URI }}</td></tr>
+  <tr><th class="text">Database</th><td class="text">{{ .Database }}</td></tr>
+  <tr><th class="text">Collection</th><td class="text">{{ .Collection }}</td></tr>
+  <tr><th class="text">Parallel</th><td class="text">{{ .Parallel }}</td></tr>
+  <tr><th class="text">Repeat</th><td class="text">{{ .Repeat }}</td></tr>
+  <tr><th class="text">Elapsed</th><td class="text">{{ .Elapsed }}</td></tr>
+</table>
+
+<h2>Queries</h2>
+<table>
+  <tr>
+    <th class="text">Name</th><th class="text">Action</th><th>QueryCount</th><th>EPS</th>
+    <th>QueryAvg</th><th>P50</th><th>P95</th><th>P99</th><th>Max</th>
+    <th>ChangeCount</th><th>ErrorCount</th><th class="text">LastError</th>
+  </tr>
+  {{- range .Results }}
+  <tr>
+    <td class="text">{{ .Name }}</td><td class="text">{{ .Action }}</td><td>{{ .QueryCount }}</td><td>{{ printf "%.2f" .EPS }}</td>
+    <td>{{ .QueryAvg }}</td><td>{{ .P50 }}</td><td>{{ .P95 }}</td><td>{{ .P99 }}</td><td>{{ .Max }}</td>
+    <td>{{ .ChangeCount }}</td><td>{{ .ErrorCount }}</td><td class="text{{ if not .Successful }} error{{ end }}">{{ .LastError }}</td>
+  </tr>
+  {{- end }}
+</table>
+
+<h2>Throughput over time</h2>
+<div class="chart"><canvas id="throughput" width="900" height="300"></canvas><div class="legend" id="throughput-legend"></div></div>
+
+<h2>Latency over time</h2>
+<div class="chart"><canvas id="latency" width="900" height="300"></canvas><div class="legend" id="latency-legend"></div></div>
+
+<h2>Latency histograms</h2>
+<div class="charts" id="histograms"></div>
+
+<script>
+(function() {
+  var report = {{ . }};
+  var histogramMin = {{ histogramMin }};
+  var histogramGrowth = {{ histogramGrowth }};
+  var colors = ["#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf"];
+  var interval = (report.timelineInterval || 1e9) / 1e9;
+  var results = report.results || [];
+
+  function ms(ns) { return ns / 1e6; }
+
+  function legend(id, names) {
+    var el = document.getElementById(id);
+    names.forEach(function(name, i) {
+      var span = document.createElement("span");
+      var swatch = document.createElement("i");
+      swatch.style.background = colors[i % colors.length];
+      span.appendChild(swatch);
+      span.appendChild(document.createTextNode(name));
+      el.appendChild(span);
+    });
+  }
+
+  function axes(ctx, w, h, pad, maxX, maxY, xLabel, yLabel, xFormat) {
+    xFormat = xFormat || function(x) { return x.toFixed(0); };
+    ctx.strokeStyle = "#999";
+    ctx.fillStyle = "#444";
+    ctx.font = "11px sans-serif";
+    ctx.beginPath();
+    ctx.moveTo(pad, pad);
+    ctx.lineTo(pad, h - pad);
+    ctx.lineTo(w - pad, h - pad);
+    ctx.stroke();
+    for (var i = 0; i <= 4; i++) {
+      var y = h - pad - (h - 2 * pad) * i / 4;
+      ctx.fillText((maxY * i / 4).toPrecision(3), 2, y + 3);
+      var x = pad + (w - 2 * pad) * i / 4;
+      ctx.fillText(xFormat(maxX * i / 4), x - 5, h - pad + 14);
+    }
+    ctx.fillText(yLabel, pad + 4, pad - 6);
+    ctx.fillText(xLabel, w - pad - 40, h - 4);
+  }
+
+  function lineChart(id, series, yLabel) {
+    var canvas = document.getElementById(id);
+    var ctx = canvas.getContext("2d");
+    var w = canvas.width, h = canvas.height, pad = 45;
+    var maxX = 1, maxY = 0;
+    series.forEach(function(s) {
+      s.points.forEach(function(p) {
+        maxX = Math.max(maxX, p[0]);
+        maxY = Math.max(maxY, p[1]);
+      });
+    });
+    if (maxY === 0) { maxY = 1; }
+    axes(ctx, w, h, pad, maxX, maxY, "seconds", yLabel);
+    series.forEach(function(s, i) {
+      ctx.strokeStyle = colors[i % colors.length];
+      ctx.lineWidth = 1.5;
+      ctx.beginPath();
+      s.points.forEach(function(p, j) {
+        var x = pad + (w - 2 * pad) * p[0] / maxX;
+        var y = h - pad - (h - 2 * pad) * p[1] / maxY;
+        if (j === 0) { ctx.moveTo(x, y); } else { ctx.lineTo(x, y); }
+      });
+      ctx.stroke();
+    });
+    legend(id + "-legend", series.map(function(s) { return s.name; }));
+  }
+
+  // bucket returns the index of a histogram bucket from its upper bound.
+  function bucket(upperBound) {
+    return Math.round(Math.log(upperBound / histogramMin) / Math.log(histogramGrowth));
+  }
+
+  function bound(i) { return histogramMin * Math.pow(histogramGrowth, i); }
+
+  // histogram plots every bucket between the lowest and the highest
+  // non empty ones, so that the axis is logarithmic and gaps are visible.
+  function histogram(result, color) {
+    var buckets = result.histogram || [];
+    var first = buckets.length > 0 ? bucket(buckets[0].upperBound) : 0;
+    var last = buckets.length > 0 ? bucket(buckets[buckets.length - 1].upperBound) : 0;
+    var counts = [];
+    for (var i = first; i <= last; i++) { counts.push(0); }
+    buckets.forEach(function(b) { counts[bucket(b.upperBound) - first] += b.count; });
+    var wrapper = document.createElement("div");
+    var title = document.createElement("div");
+    title.textContent = result.name + " (ms)";
+    var canvas = document.createElement("canvas");
+    canvas.width = 440;
+    canvas.height = 220;
+    wrapper.appendChild(title);
+    wrapper.appendChild(canvas);
+    document.getElementById("histograms").appendChild(wrapper);
+
+    var ctx = canvas.getContext("2d");
+    var w = canvas.width, h = canvas.height, pad = 45;
+    var maxY = 1;
+    counts.forEach(function(n) { maxY = Math.max(maxY, n); });
+    axes(ctx, w, h, pad, counts.length, maxY, "ms (log)", "count", function(x) {
+      return ms(bound(first + x)).toPrecision(2);
+    });
+    var barWidth = (w - 2 * pad) / Math.max(counts.length, 1);
+    ctx.fillStyle = color;
+    counts.forEach(function(n, i) {
+      var barHeight = (h - 2 * pad) * n / maxY;
+      ctx.fillRect(pad + i * barWidth, h - pad - barHeight, Math.max(barWidth - 1, 1), barHeight);
+    });
+  }
+
+  lineChart("throughput", results.map(function(r) {
+    return {name: r.name, points: (r.timeline || []).map(function(s, i) { return [i * interval, s.ops / interval]; })};
+  }), "ops/sec");
+
+  lineChart("latency", results.map(function(r) {
+    return {name: r.name, points: (r.timeline || []).map(function(s, i) {
+      return [i * interval, s.ops > 0 ? ms(s.latencySum / s.ops) : 0];
+    })};
+  }), "avg ms");
+
+  results.forEach(function(r, i) { histogram(r, colors[i % colors.length]); });
+})();
+</script>
+</body>
+</html>
+`
+
+// GenerateHTMLReport writes a self-contained HTML report.
+func GenerateHTMLReport(w io.Writer, r *Report) error {
+	t, err := template.New("html-report").Funcs(template.FuncMap{
+		"histogramMin":    func() int64 { return int64(histogramMin) },
+		"histogramGrowth": func() float64 { return histogramGrowth },
+	}).Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, r)
+}
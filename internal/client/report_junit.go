@@ -0,0 +1,108 @@
+package client
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     float64          `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       float64         `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// GenerateJUnitReport writes a JUnit XML report where every query is a
+// testcase, failing when one of its thresholds is breached.
+func GenerateJUnitReport(w io.Writer, r *Report) error {
+	className := fmt.Sprintf("mongoperf.%v.%v", r.Database, r.Collection)
+	suite := junitTestSuite{
+		Name:      className,
+		Time:      r.Elapsed.Seconds(),
+		Timestamp: r.Start.Format("2006-01-02T15:04:05"),
+		Properties: []junitProperty{
+			{Name: "version", Value: r.Version},
+			{Name: "database", Value: r.Database},
+			{Name: "collection", Value: r.Collection},
+			{Name: "parallel", Value: fmt.Sprint(r.Parallel)},
+			{Name: "repeat", Value: fmt.Sprint(r.Repeat)},
+		},
+	}
+	results := r.Results
+	if r.Total != nil && r.Total.Thresholds != nil {
+		results = append(append([]*ReportQueryResult(nil), results...), r.Total)
+	}
+	for _, res := range results {
+		tc := junitTestCase{
+			Name:      res.Name,
+			ClassName: className,
+			Time:      res.TotalTime.Seconds(),
+			SystemOut: fmt.Sprintf("queries=%d eps=%.2f p50=%v p95=%v p99=%v max=%v errors=%d lastError=%v",
+				res.QueryCount, res.EPS, res.P50, res.P95, res.P99, res.Max, res.ErrorCount, res.LastError),
+		}
+		if breaches := res.CheckThresholds(); len(breaches) > 0 {
+			var messages []string
+			for _, b := range breaches {
+				messages = append(messages, b.Message)
+			}
+			tc.Failure = &junitFailure{
+				Message: strings.Join(messages, "; "),
+				Type:    "threshold",
+				Text:    strings.Join(messages, "\n"),
+			}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suites := junitTestSuites{
+		Name:     "mongoperf",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
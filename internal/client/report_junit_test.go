@@ -0,0 +1,117 @@
+package client
+
+import (
+	"bytes"
+	"encoding/xml"
+	"mongoperf/internal/client/query"
+	"testing"
+	"time"
+)
+
+func TestGenerateJUnitReport(t *testing.T) {
+	zero, quarter := 0, 0.25
+	tests := []struct {
+		name         string
+		results      []*ReportQueryResult
+		total        *ReportQueryResult
+		wantTests    int
+		wantFailures []string
+	}{
+		{
+			name: "passing queries",
+			results: []*ReportQueryResult{
+				{Name: "find", QueryCount: 10, Successful: true, LastError: "nil"},
+				{Name: "insert", QueryCount: 10, Successful: true, LastError: "nil",
+					Thresholds: &query.Thresholds{MaxErrors: &zero}},
+			},
+			total:        &ReportQueryResult{Name: "total", QueryCount: 20},
+			wantTests:    2,
+			wantFailures: []string{"", ""},
+		},
+		{
+			// failed queries only fail their testcase through a threshold
+			name: "failed queries",
+			results: []*ReportQueryResult{
+				{Name: "find", QueryCount: 10, ErrorCount: 2, LastError: "timeout"},
+				{Name: "insert", QueryCount: 10, ErrorCount: 3, LastError: "duplicate key",
+					Thresholds: &query.Thresholds{MaxErrors: &zero}},
+			},
+			total:        &ReportQueryResult{Name: "total", QueryCount: 20, ErrorCount: 5},
+			wantTests:    2,
+			wantFailures: []string{"", "error count 3 exceeds 0 by 3"},
+		},
+		{
+			name: "breached global threshold",
+			results: []*ReportQueryResult{
+				{Name: "find", QueryCount: 10, ErrorCount: 5, LastError: "timeout"},
+			},
+			total: &ReportQueryResult{Name: "total", QueryCount: 10, ErrorCount: 5,
+				Thresholds: &query.Thresholds{MaxErrors: &zero, MaxErrorRate: &quarter}},
+			wantTests:    2,
+			wantFailures: []string{"", "error count 5 exceeds 0 by 5; error rate 50.00% exceeds 25.00% by 25.00 points"},
+		},
+		{
+			name: "escaping",
+			results: []*ReportQueryResult{
+				{Name: `find <"a"> & b`, QueryCount: 1, ErrorCount: 1, LastError: `bad <value> & "quote"`,
+					Thresholds: &query.Thresholds{MaxErrors: &zero}},
+			},
+			wantTests:    1,
+			wantFailures: []string{"error count 1 exceeds 0 by 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Report{
+				Database:   "perf",
+				Collection: "users",
+				Elapsed:    time.Second,
+				Results:    tt.results,
+				Total:      tt.total,
+			}
+			var b bytes.Buffer
+			if err := GenerateJUnitReport(&b, r); err != nil {
+				t.Fatalf("GenerateJUnitReport() error = %v", err)
+			}
+			var suites junitTestSuites
+			if err := xml.Unmarshal(b.Bytes(), &suites); err != nil {
+				t.Fatalf("invalid XML: %v\n%s", err, b.String())
+			}
+			failures := 0
+			for _, f := range tt.wantFailures {
+				if f != "" {
+					failures++
+				}
+			}
+			if suites.Tests != tt.wantTests || suites.Failures != failures || len(suites.Suites) != 1 {
+				t.Fatalf("got %d tests, %d failures and %d suites, want %d tests and %d failures in 1 suite",
+					suites.Tests, suites.Failures, len(suites.Suites), tt.wantTests, failures)
+			}
+			suite := suites.Suites[0]
+			if suite.Name != "mongoperf.perf.users" || suite.Tests != tt.wantTests || suite.Failures != failures {
+				t.Errorf("got suite %v with %d tests and %d failures", suite.Name, suite.Tests, suite.Failures)
+			}
+			if len(suite.Cases) != len(tt.wantFailures) {
+				t.Fatalf("got %d testcases, want %d", len(suite.Cases), len(tt.wantFailures))
+			}
+			for i, tc := range suite.Cases {
+				if i < len(tt.results) && tc.Name != tt.results[i].Name {
+					t.Errorf("testcase %d: got name %q, want %q", i, tc.Name, tt.results[i].Name)
+				}
+				got := ""
+				if tc.Failure != nil {
+					got = tc.Failure.Message
+				}
+				if got != tt.wantFailures[i] {
+					t.Errorf("testcase %v: got failure %q, want %q", tc.Name, got, tt.wantFailures[i])
+				}
+			}
+			if tt.name == "escaping" {
+				want := `queries=1 eps=0.00 p50=0s p95=0s p99=0s max=0s errors=1 lastError=bad <value> & "quote"`
+				if got := suite.Cases[0].SystemOut; got != want {
+					t.Errorf("got system-out %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gopkg.in/yaml.v2"
+)
+
+// Scenario templates .
+const (
+	ReadHeavyTemplate    = "read-heavy"
+	WriteHeavyTemplate   = "write-heavy"
+	MixedTemplate        = "mixed"
+	IngestTemplate       = "ingest"
+	TransactionsTemplate = "transactions"
+)
+
+// ScenarioTemplates lists the available scenario templates.
+var ScenarioTemplates = []string{
+	ReadHeavyTemplate,
+	WriteHeavyTemplate,
+	MixedTemplate,
+	IngestTemplate,
+	TransactionsTemplate,
+}
+
+// ScenarioSample describes the documents of a collection
+// and is used to generate the queries of a scenario.
+type ScenarioSample struct {
+	Database   string
+	Collection string
+	// Sampled is the number of documents sampled, 0 for the defaults.
+	Sampled int
+	// Document is the document inserted by the scenario.
+	Document yaml.MapSlice
+	// KeyField is the most selective field, used for lookups.
+	KeyField string
+	KeyValue interface{}
+	// GroupField is a field shared by several documents, used for lists.
+	GroupField string
+	GroupValue interface{}
+	// CounterField is a numeric field incremented by updates, if any.
+	CounterField string
+}
+
+// DefaultScenarioSample returns the sample used when
+// no collection is sampled.
+func DefaultScenarioSample(database, collection string) *ScenarioSample {
+	return &ScenarioSample{
+		Database:   database,
+		Collection: collection,
+		Document: yaml.MapSlice{
+			{Key: "name", Value: "Ash"},
+			{Key: "age", Value: 10},
+			{Key: "city", Value: "Pallet Town"},
+			{Key: "badges", Value: 0},
+		},
+		KeyField:     "name",
+		KeyValue:     "Ash",
+		GroupField:   "city",
+		GroupValue:   "Pallet Town",
+		CounterField: "badges",
+	}
+}
+
+type sampledField struct {
+	name     string
+	present  int
+	numeric  bool
+	distinct map[interface{}]bool
+}
+
+// SampleCollection samples up to size documents of the collection and
+// infers the fields used by the generated queries. The first document
+// sampled, without its _id, is used as the inserted document.
+func (c *Client) SampleCollection(ctx context.Context, database, collection string, size int) (*ScenarioSample, error) {
+	col := c.client.Database(database).Collection(collection)
+	pipeline := bson.A{bson.D{{Key: "$sample", Value: bson.D{{Key: "size", Value: size}}}}}
+	cur, err := col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	sample := &ScenarioSample{Database: database, Collection: collection}
+	var fields []*sampledField
+	byName := make(map[string]*sampledField)
+	for cur.Next(ctx) {
+		var doc bson.D
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		if sample.Sampled == 0 {
+			sample.Document = yamlDocument(doc, true)
+		}
+		sample.Sampled++
+		for _, e := range doc {
+			if e.Key == "_id" {
+				continue
+			}
+			v, numeric, ok := sampleScalar(e.Value)
+			if !ok {
+				continue
+			}
+			f, found := byName[e.Key]
+			if !found {
+				f = &sampledField{name: e.Key, numeric: numeric, distinct: make(map[interface{}]bool)}
+				byName[e.Key] = f
+				fields = append(fields, f)
+			}
+			f.present++
+			f.distinct[v] = true
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+	if sample.Sampled == 0 {
+		return nil, fmt.Errorf("collection %v.%v is empty", database, collection)
+	}
+
+	var key, group *sampledField
+	for _, f := range fields {
+		if f.present < sample.Sampled {
+			continue
+		}
+		if key == nil || len(f.distinct) > len(key.distinct) {
+			key = f
+		}
+		if len(f.distinct) >= 2 && len(f.distinct) < sample.Sampled && (group == nil || len(f.distinct) < len(group.distinct)) {
+			group = f
+		}
+		if f.numeric && sample.CounterField == "" {
+			sample.CounterField = f.name
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("no scalar field is present in every sampled document of %v.%v", database, collection)
+	}
+	if group == nil {
+		group = key
+	}
+	sample.KeyField, sample.KeyValue = key.name, documentValue(sample.Document, key.name)
+	sample.GroupField, sample.GroupValue = group.name, documentValue(sample.Document, group.name)
+	return sample, nil
+}
+
+// sampleScalar returns the comparable value of scalar
+// BSON values, and whether it is numeric.
+func sampleScalar(v interface{}) (interface{}, bool, bool) {
+	switch v := v.(type) {
+	case string, bool:
+		return v, false, true
+	case int32, int64, float64:
+		return v, true, true
+	}
+	return nil, false, false
+}
+
+func documentValue(doc yaml.MapSlice, key string) interface{} {
+	for _, item := range doc {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+// yamlDocument converts a BSON document to a YAML mapping.
+func yamlDocument(doc bson.D, skipID bool) yaml.MapSlice {
+	m := make(yaml.MapSlice, 0, len(doc))
+	for _, e := range doc {
+		if skipID && e.Key == "_id" {
+			continue
+		}
+		m = append(m, yaml.MapItem{Key: e.Key, Value: yamlValue(e.Value)})
+	}
+	return m
+}
+
+// yamlValue converts a BSON value to a value which can be
+// written in a scenario. Types which cannot be expressed
+// in YAML are written as strings.
+func yamlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case bson.D:
+		return yamlDocument(v, false)
+	case bson.A:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = yamlValue(val)
+		}
+		return s
+	case string, bool, int32, int64, float64, nil:
+		return v
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return time.Unix(0, int64(v)*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+var scaffoldFuncs = template.FuncMap{
+	// yaml returns a value formatted as an inline YAML value.
+	"yaml": func(v interface{}) (string, error) {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(string(b), "\n"), nil
+	},
+	// batch returns a list made of n copies of v.
+	"batch": func(n int, v interface{}) []interface{} {
+		s := make([]interface{}, n)
+		for i := range s {
+			s[i] = v
+		}
+		return s
+	},
+	// indent returns a value formatted as a YAML block indented by n spaces.
+	"indent": func(n int, v interface{}) (string, error) {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+		pad := strings.Repeat(" ", n)
+		return pad + strings.Join(lines, "\n"+pad), nil
+	},
+}
+
+// GenerateScenario writes a commented scenario file using the template,
+// whose queries target the fields of the sample.
+func GenerateScenario(w io.Writer, name string, sample *ScenarioSample) error {
+	body, ok := scenarioTemplates[name]
+	if !ok {
+		return fmt.Errorf("template not supported: %v", name)
+	}
+	tmpl, err := template.New("scenario").Funcs(scaffoldFuncs).Parse(scenarioHeaderTemplate + body)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, struct {
+		Template string
+		*ScenarioSample
+	}{name, sample})
+}
+
+const scenarioHeaderTemplate = `---
+# mongoperf scenario generated using the {{ .Template }} template.
+{{- if .Sampled }}
+# Queries were inferred from {{ .Sampled }} document(s) sampled from {{ .Database }}.{{ .Collection }}.
+{{- end }}
+# Check it using: mongoperf validate <file>
+# Run it using:   mongoperf scenario <file> --uri mongodb://localhost:27017
+
+# Database and collection the queries are sent to.
+Database: {{ yaml .Database }}
+Collection: {{ yaml .Collection }}
+`
+
+// the lookup, list and update queries shared by the templates
+const (
+	findOneQueryTemplate = `
+# Lookup of a single document using its most selective field.
+- Name: find-by-{{ .KeyField }}
+  Action: FindOneAction
+  Meta:
+    Filter:
+      {{ yaml .KeyField }}: {{ yaml .KeyValue }}
+`
+	findQueryTemplate = `
+# List of the documents sharing a value.
+- Name: list-by-{{ .GroupField }}
+  Action: Find
+  Meta:
+    Filter:
+      {{ yaml .GroupField }}: {{ yaml .GroupValue }}
+    Options:
+      Limit: 20
+`
+	updateQueryTemplate = `
+# Update of a single document.
+- Name: update-by-{{ .KeyField }}
+  Action: UpdateOneAction
+  Meta:
+    Filter:
+      {{ yaml .KeyField }}: {{ yaml .KeyValue }}
+    Data:
+{{- if .CounterField }}
+      $inc:
+        {{ yaml .CounterField }}: 1
+{{- else }}
+      $set:
+        updatedBy: mongoperf
+{{- end }}
+`
+	insertQueryTemplate = `
+# Insert of a single document.
+- Name: insert-one
+  Action: InsertOne
+  Meta:
+    Data:
+{{ indent 6 .Document }}
+`
+	deleteQueryTemplate = `
+# Delete of a single document, matching the inserted ones.
+- Name: delete-by-{{ .KeyField }}
+  Action: DeleteOne
+  Meta:
+    Filter:
+      {{ yaml .KeyField }}: {{ yaml .KeyValue }}
+`
+	thresholdsTemplate = `
+# Uncomment to fail the run, with exit code 3, when the
+# results of the whole run do not respect these limits.
+# Thresholds:
+#   MaxErrorRate: 0.01
+#   MaxP99: 50ms
+`
+)
+
+var scenarioTemplates = map[string]string{
+	ReadHeavyTemplate: `
+# Number of workers sending queries concurrently.
+Parallel: 8
+# Number of times the list of queries is sent. 0 repeats until interrupted.
+Repeat: 1000
+` + thresholdsTemplate + `
+# Queries are sent in order: three reads for each write.
+Queries:` + findOneQueryTemplate + findQueryTemplate + `
+# Lookup returning only the selective field.
+- Name: find-by-{{ .KeyField }}-projected
+  Action: FindOneAction
+  Meta:
+    Filter:
+      {{ yaml .KeyField }}: {{ yaml .KeyValue }}
+    Options:
+      Projection:
+        {{ yaml .KeyField }}: 1
+` + updateQueryTemplate,
+
+	WriteHeavyTemplate: `
+# Number of workers sending queries concurrently.
+Parallel: 8
+# Number of times the list of queries is sent. 0 repeats until interrupted.
+Repeat: 1000
+` + thresholdsTemplate + `
+# Queries are sent in order: three writes for each read.
+Queries:` + insertQueryTemplate + updateQueryTemplate + deleteQueryTemplate + findOneQueryTemplate,
+
+	MixedTemplate: `
+# Number of workers sending queries concurrently.
+Parallel: 4
+# Number of times the list of queries is sent. 0 repeats until interrupted.
+Repeat: 1000
+` + thresholdsTemplate + `
+# Queries are sent in order: as many reads as writes.
+Queries:` + insertQueryTemplate + findOneQueryTemplate + findQueryTemplate + updateQueryTemplate,
+
+	IngestTemplate: `
+# Number of workers sending queries concurrently.
+Parallel: 8
+# Number of queries waiting for a worker.
+BufferSize: 100
+# Number of times the list of queries is sent. 0 repeats until interrupted.
+Repeat: 1000
+# Connection pool of each server, raised to match Parallel.
+MaxPoolSize: 16
+` + thresholdsTemplate + `
+Queries:
+# Unordered batch insert, the server continues after a failed document.
+- Name: insert-batch
+  Action: InsertMany
+  Meta:
+    Data:
+{{ indent 6 (batch 10 .Document) }}
+    Options:
+      Ordered: false
+`,
+
+	TransactionsTemplate: `
+# Transactions require a replica set or a sharded cluster.
+# Number of workers sending queries concurrently.
+Parallel: 4
+# Number of times the list of queries is sent. 0 repeats until interrupted.
+Repeat: 500
+` + thresholdsTemplate + `
+Queries:
+# Insert and update committed together. The driver retries the
+# transaction on transient errors, such as write conflicts.
+- Name: insert-and-update
+  Action: Transaction
+  Meta:
+    Operations:
+    - Action: InsertOne
+      Meta:
+        Data:
+{{ indent 10 .Document }}
+    - Action: UpdateOneAction
+      Meta:
+        Filter:
+          {{ yaml .KeyField }}: {{ yaml .KeyValue }}
+        Data:
+{{- if .CounterField }}
+          $inc:
+            {{ yaml .CounterField }}: 1
+{{- else }}
+          $set:
+            updatedBy: mongoperf
+{{- end }}
+` + findOneQueryTemplate,
+}
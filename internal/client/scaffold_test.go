@@ -0,0 +1,51 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestGenerateScenario(t *testing.T) {
+	samples := map[string]*ScenarioSample{
+		"default": DefaultScenarioSample("mongoperf", "users"),
+		"sampled": {
+			Database:   "shop",
+			Collection: "orders: 2020",
+			Sampled:    10,
+			Document: yaml.MapSlice{
+				{Key: "sku", Value: "a#1"},
+				{Key: "status", Value: "yes"},
+				{Key: "lines", Value: []interface{}{yaml.MapSlice{{Key: "qty", Value: 2}}}},
+			},
+			KeyField:   "sku",
+			KeyValue:   "a#1",
+			GroupField: "status",
+			GroupValue: "yes",
+		},
+	}
+	for _, name := range ScenarioTemplates {
+		for sampleName, sample := range samples {
+			t.Run(name+"/"+sampleName, func(t *testing.T) {
+				var b bytes.Buffer
+				if err := GenerateScenario(&b, name, sample); err != nil {
+					t.Fatalf("GenerateScenario() error = %v", err)
+				}
+				s, err := ParseScenario(b.Bytes(), nil)
+				if err != nil {
+					t.Fatalf("ParseScenario() error = %v\n%s", err, b.String())
+				}
+				if *s.Database != sample.Database || *s.Collection != sample.Collection || len(s.Queries) == 0 {
+					t.Errorf("got %v.%v with %d queries", *s.Database, *s.Collection, len(s.Queries))
+				}
+				if problems := ValidateScenario(b.Bytes(), nil); len(problems) > 0 {
+					t.Errorf("ValidateScenario() = %v\n%s", problems, b.String())
+				}
+			})
+		}
+	}
+	if err := GenerateScenario(&bytes.Buffer{}, "bogus", samples["default"]); err == nil {
+		t.Errorf("GenerateScenario(bogus) succeeded")
+	}
+}
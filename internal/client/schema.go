@@ -0,0 +1,299 @@
+package client
+
+import (
+	"fmt"
+	"mongoperf/internal/client/query"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// JSONSchemaDraft is the JSON Schema version of the generated schemas.
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema is a JSON Schema document, limited to
+// the keywords used to describe scenarios.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Const                interface{}            `json:"const,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	AllOf                []*JSONSchema          `json:"allOf,omitempty"`
+	If                   *JSONSchema            `json:"if,omitempty"`
+	Then                 *JSONSchema            `json:"then,omitempty"`
+
+	// fields holds the property names in declaration order.
+	fields []string
+	// duration is set for schemas of time.Duration values.
+	duration bool
+	// foldCase is set for schemas of Meta types, whose
+	// keys are matched ignoring case by mapstructure.
+	foldCase bool
+}
+
+// Fields returns the property names in declaration order.
+func (s *JSONSchema) Fields() []string {
+	return s.fields
+}
+
+var (
+	durationType   = reflect.TypeOf(time.Duration(0))
+	actionType     = reflect.TypeOf(query.Action(""))
+	definitionType = reflect.TypeOf(query.Definition{})
+	operationType  = reflect.TypeOf(query.TransactionOperation{})
+)
+
+// schemaBuilder generates the schema of Go types.
+//
+// Meta types are decoded by mapstructure, which uses the field
+// names and expects durations in nanoseconds. Scenario types are
+// decoded by the yaml package, which uses the yaml tags and
+// parses durations such as 20ms.
+type schemaBuilder struct {
+	yaml bool
+}
+
+func (b *schemaBuilder) build(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == durationType && b.yaml:
+		return &JSONSchema{Type: "string", Pattern: `^-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$`, duration: true}
+	case t == durationType:
+		return &JSONSchema{Type: "integer", duration: true}
+	case t == actionType:
+		return &JSONSchema{Type: "string", Enum: actionEnum()}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer", Minimum: Float64(0)}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: b.build(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object"}
+	case reflect.Struct:
+		return b.buildStruct(t)
+	}
+	// interfaces accept any value
+	return &JSONSchema{}
+}
+
+func (b *schemaBuilder) buildStruct(t reflect.Type) *JSONSchema {
+	s := &JSONSchema{
+		Title:                t.Name(),
+		Type:                 "object",
+		Properties:           make(map[string]*JSONSchema),
+		AdditionalProperties: Bool(false),
+		foldCase:             !b.yaml,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := b.fieldName(f)
+		if !ok || !settable(f.Type) {
+			continue
+		}
+		fs := b.build(f.Type)
+		if doc := f.Tag.Get("doc"); doc != "" {
+			fs.Description = doc
+		}
+		if def := f.Tag.Get("default"); def != "" {
+			var v interface{}
+			if err := yaml.Unmarshal([]byte(def), &v); err == nil {
+				fs.Default = query.JSONValue(v)
+			}
+		}
+		if min := f.Tag.Get("minimum"); min != "" {
+			var v float64
+			if _, err := fmt.Sscan(min, &v); err == nil {
+				fs.Minimum = &v
+			}
+		}
+		if max := f.Tag.Get("maximum"); max != "" {
+			var v float64
+			if _, err := fmt.Sscan(max, &v); err == nil {
+				fs.Maximum = &v
+			}
+		}
+		if min := f.Tag.Get("minItems"); min != "" {
+			var v int
+			if _, err := fmt.Sscan(min, &v); err == nil {
+				fs.MinItems = &v
+			}
+		}
+		if f.Tag.Get("required") == "true" {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+		s.fields = append(s.fields, name)
+	}
+	// the Meta of queries and transaction operations depends on their Action
+	switch t {
+	case definitionType:
+		s.AllOf = actionConditions(false)
+	case operationType:
+		// transactions cannot be nested
+		s.Properties["Action"].Enum = actionEnum(query.TransactionAction)
+		s.AllOf = actionConditions(true, query.TransactionAction)
+	}
+	return s
+}
+
+func (b *schemaBuilder) fieldName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	if !b.yaml {
+		return f.Name, true
+	}
+	name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return strings.ToLower(f.Name), true
+	}
+	return name, true
+}
+
+// settable reports whether a value of the type can be
+// decoded from YAML. Structs without exported fields,
+// such as the driver read and write concerns, cannot.
+func settable(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	case reflect.Struct:
+		if t == durationType {
+			return true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath == "" {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ScenarioSchema returns the schema of a scenario file.
+func ScenarioSchema() *JSONSchema {
+	b := &schemaBuilder{yaml: true}
+	s := b.build(reflect.TypeOf(Scenario{}))
+	s.Schema = JSONSchemaDraft
+	s.Title = "mongoperf scenario"
+	return s
+}
+
+// ActionSchema returns the schema of the Meta of the action.
+func ActionSchema(a query.Action) (*JSONSchema, error) {
+	meta, err := query.NewMeta(a)
+	if err != nil {
+		return nil, err
+	}
+	b := &schemaBuilder{}
+	s := b.build(reflect.TypeOf(meta))
+	s.Title = string(a) + " Meta"
+	return s, nil
+}
+
+// ActionsSchema returns the schema of a query definition,
+// whose Meta is described according to its Action.
+func ActionsSchema() *JSONSchema {
+	s := &JSONSchema{
+		Schema: JSONSchemaDraft,
+		Title:  "mongoperf query",
+		Type:   "object",
+		Properties: map[string]*JSONSchema{
+			"Action": (&schemaBuilder{}).build(actionType),
+			"Meta":   {Type: "object"},
+		},
+		Required: []string{"Action", "Meta"},
+		fields:   []string{"Action", "Meta"},
+	}
+	s.AllOf = actionConditions(false)
+	return s
+}
+
+// actionEnum returns every action but the excluded ones.
+func actionEnum(exclude ...query.Action) []interface{} {
+	var enum []interface{}
+	for _, a := range query.Actions {
+		if !containsAction(exclude, a) {
+			enum = append(enum, string(a))
+		}
+	}
+	return enum
+}
+
+// actionConditions returns, for every action but the excluded
+// ones, a condition applying the schema of its Meta. The Action
+// and Meta keys are matched ignoring case when foldCase is set.
+func actionConditions(foldCase bool, exclude ...query.Action) []*JSONSchema {
+	var conditions []*JSONSchema
+	for _, a := range query.Actions {
+		if containsAction(exclude, a) {
+			continue
+		}
+		meta, err := ActionSchema(a)
+		if err != nil {
+			continue
+		}
+		conditions = append(conditions, &JSONSchema{
+			If: &JSONSchema{
+				Properties: map[string]*JSONSchema{"Action": {Const: string(a)}},
+				Required:   []string{"Action"},
+				foldCase:   foldCase,
+			},
+			Then: &JSONSchema{
+				Properties: map[string]*JSONSchema{"Meta": meta},
+				foldCase:   foldCase,
+			},
+		})
+	}
+	return conditions
+}
+
+func containsAction(actions []query.Action, a query.Action) bool {
+	for _, action := range actions {
+		if action == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Bool .
+func Bool(b bool) *bool {
+	return &b
+}
+
+// Float64 .
+func Float64(f float64) *float64 {
+	return &f
+}
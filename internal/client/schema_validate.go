@@ -0,0 +1,211 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaError is a value which does not respect its schema.
+type SchemaError struct {
+	// Path locates the value, such as Queries[0].Meta.Filter.
+	Path    string
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Validate returns the errors found validating the value,
+// decoded from YAML or JSON, against the schema.
+func (s *JSONSchema) Validate(v interface{}) []SchemaError {
+	return s.validate(schemaValue(v), "")
+}
+
+func (s *JSONSchema) validate(v interface{}, path string) []SchemaError {
+	fail := func(format string, a ...interface{}) []SchemaError {
+		return []SchemaError{{Path: path, Message: fmt.Sprintf(format, a...)}}
+	}
+	if s.Type != "" && !schemaTypeOf(s.Type, v) {
+		return fail("must be of type %v", s.Type)
+	}
+	if len(s.Enum) > 0 && !schemaContains(s.Enum, v) {
+		values := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			values = append(values, fmt.Sprint(e))
+		}
+		return fail("must be one of: %v", strings.Join(values, ", "))
+	}
+	if s.Const != nil && !reflect.DeepEqual(s.Const, v) {
+		return fail("must be %v", s.Const)
+	}
+
+	var errs []SchemaError
+	if n, ok := schemaNumber(v); ok {
+		if s.Minimum != nil && n < *s.Minimum {
+			errs = append(errs, fail("must be greater than or equal to %v", *s.Minimum)...)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			errs = append(errs, fail("must be less than or equal to %v", *s.Maximum)...)
+		}
+	}
+	if str, ok := v.(string); ok && s.Pattern != "" {
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+			if s.duration {
+				errs = append(errs, fail("must be a duration, such as 20ms")...)
+			} else {
+				errs = append(errs, fail("must match %v", s.Pattern)...)
+			}
+		}
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if !s.hasKey(v, name) {
+				errs = append(errs, SchemaError{Path: joinPath(path, name), Message: "must not be empty"})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ps, ok := s.property(k)
+			switch {
+			case ok:
+				errs = append(errs, ps.validate(v[k], joinPath(path, k))...)
+			case s.AdditionalProperties != nil && !*s.AdditionalProperties:
+				errs = append(errs, SchemaError{Path: joinPath(path, k), Message: "unknown key"})
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			errs = append(errs, fail("must contain at least %d item(s)", *s.MinItems)...)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				errs = append(errs, s.Items.validate(item, fmt.Sprintf("%v[%d]", path, i))...)
+			}
+		}
+	}
+	for _, sub := range s.AllOf {
+		errs = append(errs, sub.validate(v, path)...)
+	}
+	if s.If != nil && s.Then != nil && len(s.If.validate(v, path)) == 0 {
+		errs = append(errs, s.Then.validate(v, path)...)
+	}
+	return errs
+}
+
+// property returns the schema of the key.
+func (s *JSONSchema) property(key string) (*JSONSchema, bool) {
+	if ps, ok := s.Properties[key]; ok || !s.foldCase {
+		return ps, ok
+	}
+	for name, ps := range s.Properties {
+		if strings.EqualFold(name, key) {
+			return ps, true
+		}
+	}
+	return nil, false
+}
+
+// hasKey reports whether the object holds the key.
+func (s *JSONSchema) hasKey(v map[string]interface{}, key string) bool {
+	if _, ok := v[key]; ok || !s.foldCase {
+		return ok
+	}
+	for k := range v {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// schemaValue converts the maps decoded from YAML to maps with
+// string keys, and integers to int64.
+func schemaValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = schemaValue(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = schemaValue(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = schemaValue(val)
+		}
+		return s
+	case int:
+		return int64(v)
+	}
+	return v
+}
+
+func schemaNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func schemaTypeOf(t string, v interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "integer":
+		n, ok := schemaNumber(v)
+		return ok && n == float64(int64(n))
+	case "number":
+		_, ok := schemaNumber(v)
+		return ok
+	}
+	return true
+}
+
+func schemaContains(values []interface{}, v interface{}) bool {
+	for _, e := range values {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestScenarioSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []SchemaError
+	}{
+		{
+			name: "valid",
+			in: `Database: db
+Collection: c
+Parallel: 2
+MaxConnIdleTime: 20ms
+Queries:
+  - Name: a
+    Action: Find
+    Meta: {Filter: {}, Options: {Limit: 1}}`,
+		},
+		{
+			name: "missing keys",
+			in:   `Parallel: 2`,
+			want: []SchemaError{
+				{Path: "Database", Message: "must not be empty"},
+				{Path: "Collection", Message: "must not be empty"},
+				{Path: "Queries", Message: "must not be empty"},
+			},
+		},
+		{
+			name: "unknown key",
+			in: `Database: db
+Collection: c
+Paralel: 2
+Queries: [{Name: a, Action: Find, Meta: {}}]`,
+			want: []SchemaError{{Path: "Paralel", Message: "unknown key"}},
+		},
+		{
+			name: "scenario keys are case sensitive",
+			in: `Database: db
+Collection: c
+parallel: 2
+Queries: [{Name: a, Action: Find, Meta: {}}]`,
+			want: []SchemaError{{Path: "parallel", Message: "unknown key"}},
+		},
+		{
+			name: "out of bounds",
+			in: `Database: db
+Collection: c
+Parallel: 0
+Queries: [{Name: a, Action: Find, Meta: {}}]`,
+			want: []SchemaError{{Path: "Parallel", Message: "must be greater than or equal to 1"}},
+		},
+		{
+			name: "wrong type",
+			in: `Database: db
+Collection: c
+Repeat: many
+Queries: [{Name: a, Action: Find, Meta: {}}]`,
+			want: []SchemaError{{Path: "Repeat", Message: "must be of type integer"}},
+		},
+		{
+			name: "duration",
+			in: `Database: db
+Collection: c
+MaxConnIdleTime: 20
+Queries: [{Name: a, Action: Find, Meta: {}}]`,
+			want: []SchemaError{{Path: "MaxConnIdleTime", Message: "must be of type string"}},
+		},
+		{
+			name: "unknown action",
+			in: `Database: db
+Collection: c
+Queries: [{Name: a, Action: Bogus, Meta: {}}]`,
+			want: []SchemaError{{Path: "Queries[0].Action", Message: "must be one of: InsertOne, InsertMany, UpdateOneAction, FindOneAction, Find, DeleteOne, Transaction"}},
+		},
+		{
+			name: "unknown meta key",
+			in: `Database: db
+Collection: c
+Queries: [{Name: a, Action: Find, Meta: {Options: {Limitt: 1}}}]`,
+			want: []SchemaError{{Path: "Queries[0].Meta.Options.Limitt", Message: "unknown key"}},
+		},
+		{
+			name: "meta keys are case insensitive",
+			in: `Database: db
+Collection: c
+Queries: [{Name: a, Action: Find, Meta: {filter: {}, options: {limit: 1}}}]`,
+		},
+		{
+			name: "transaction operation",
+			in: `Database: db
+Collection: c
+Queries:
+  - Name: a
+    Action: Transaction
+    Meta:
+      operations:
+        - action: InsertOne
+          meta: {data: {a: 1}}
+        - Action: Find
+          Meta: {Filtr: {}}`,
+			want: []SchemaError{{Path: "Queries[0].Meta.operations[1].Meta.Filtr", Message: "unknown key"}},
+		},
+		{
+			name: "nested transaction",
+			in: `Database: db
+Collection: c
+Queries:
+  - Name: a
+    Action: Transaction
+    Meta:
+      Operations:
+        - Action: Transaction
+          Meta: {}`,
+			want: []SchemaError{{Path: "Queries[0].Meta.Operations[0].Action", Message: "must be one of: InsertOne, InsertMany, UpdateOneAction, FindOneAction, Find, DeleteOne"}},
+		},
+	}
+	schema := ScenarioSchema()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc interface{}
+			if err := yaml.Unmarshal([]byte(tt.in), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if got := schema.Validate(doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
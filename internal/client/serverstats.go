@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"mongoperf/internal/client/query"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Server metrics captured in a ServerSnapshot, in report order.
+// Each entry is the path of a numeric value in the command output,
+// prefixed with the name of the command it comes from.
+var (
+	serverStatusMetrics = []string{
+		"opcounters.insert",
+		"opcounters.query",
+		"opcounters.update",
+		"opcounters.delete",
+		"opcounters.getmore",
+		"opcounters.command",
+		"metrics.document.inserted",
+		"metrics.document.returned",
+		"metrics.document.updated",
+		"metrics.document.deleted",
+		"wiredTiger.cache.bytes read into cache",
+		"wiredTiger.cache.bytes written from cache",
+		"wiredTiger.cache.pages read into cache",
+		"wiredTiger.cache.pages written from cache",
+		"wiredTiger.cache.unmodified pages evicted",
+		"wiredTiger.cache.modified pages evicted",
+		"locks.Global.acquireWaitCount.r",
+		"locks.Global.acquireWaitCount.w",
+		"locks.Global.timeAcquiringMicros.r",
+		"locks.Global.timeAcquiringMicros.w",
+		"locks.Collection.acquireWaitCount.r",
+		"locks.Collection.acquireWaitCount.w",
+		"locks.Collection.timeAcquiringMicros.r",
+		"locks.Collection.timeAcquiringMicros.w",
+	}
+	dbStatsMetrics = []string{
+		"objects",
+		"dataSize",
+		"storageSize",
+		"indexSize",
+	}
+	collStatsMetrics = []string{
+		"count",
+		"size",
+		"storageSize",
+		"totalIndexSize",
+	}
+)
+
+// ServerSnapshot holds server side metrics captured at a point in time.
+type ServerSnapshot struct {
+	Time   time.Time
+	Values map[string]int64
+}
+
+// ServerStatDelta is the difference of a metric between two snapshots.
+type ServerStatDelta struct {
+	Name   string `json:"name"`
+	Before int64  `json:"before"`
+	After  int64  `json:"after"`
+	Delta  int64  `json:"delta"`
+}
+
+// ServerSnapshot runs serverStatus, dbStats and collStats for the
+// scenario database and collection and returns the metrics of interest.
+// A command which fails, such as serverStatus without the privilege or
+// collStats on a dropped collection, is logged and its metrics left out.
+func (c *Client) ServerSnapshot(ctx context.Context, scenario *Scenario) *ServerSnapshot {
+	db := c.client.Database(*scenario.Database)
+	snapshot := &ServerSnapshot{
+		Time:   time.Now(),
+		Values: make(map[string]int64),
+	}
+	commands := []struct {
+		name    string
+		cmd     bson.D
+		metrics []string
+	}{
+		{"serverStatus", bson.D{{Key: "serverStatus", Value: 1}}, serverStatusMetrics},
+		{"dbStats", bson.D{{Key: "dbStats", Value: 1}}, dbStatsMetrics},
+		{"collStats", bson.D{{Key: "collStats", Value: *scenario.Collection}}, collStatsMetrics},
+	}
+	for _, command := range commands {
+		var out bson.M
+		if err := db.RunCommand(ctx, command.cmd).Decode(&out); err != nil {
+			c.logger.Warnf("server stats: %v skipped: %v", command.name, err)
+			continue
+		}
+		for _, path := range command.metrics {
+			if v, ok := lookupInt64(out, path); ok {
+				snapshot.Values[command.name+"."+path] = v
+			}
+		}
+	}
+	return snapshot
+}
+
+// DiffServerSnapshots returns the deltas of every metric present
+// in both snapshots.
+func DiffServerSnapshots(before, after *ServerSnapshot) []ServerStatDelta {
+	var deltas []ServerStatDelta
+	add := func(prefix string, metrics []string) {
+		for _, path := range metrics {
+			name := prefix + "." + path
+			b, okBefore := before.Values[name]
+			a, okAfter := after.Values[name]
+			if !okBefore || !okAfter {
+				continue
+			}
+			deltas = append(deltas, ServerStatDelta{Name: name, Before: b, After: a, Delta: a - b})
+		}
+	}
+	add("serverStatus", serverStatusMetrics)
+	add("dbStats", dbStatsMetrics)
+	add("collStats", collStatsMetrics)
+	return deltas
+}
+
+// lookupInt64 walks the dotted path in doc and returns
+// the numeric value found at its end.
+func lookupInt64(doc bson.M, path string) (int64, bool) {
+	keys := strings.Split(path, ".")
+	var cur interface{} = doc
+	for _, k := range keys {
+		m, ok := cur.(bson.M)
+		if !ok {
+			return 0, false
+		}
+		cur, ok = m[k]
+		if !ok {
+			return 0, false
+		}
+	}
+	switch cur.(type) {
+	case int32, int64, float64:
+		return query.ToInt64(cur), true
+	}
+	return 0, false
+}
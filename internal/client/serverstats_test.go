@@ -0,0 +1,87 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLookupInt64(t *testing.T) {
+	doc := bson.M{
+		"count":      int32(3),
+		"opcounters": bson.M{"insert": int64(12), "query": 2.9},
+		"wiredTiger": bson.M{"cache": bson.M{"bytes read into cache": int64(4096)}},
+		"host":       "localhost",
+	}
+	tests := []struct {
+		path   string
+		want   int64
+		wantOK bool
+	}{
+		{path: "count", want: 3, wantOK: true},
+		{path: "opcounters.insert", want: 12, wantOK: true},
+		{path: "opcounters.query", want: 2, wantOK: true},
+		{path: "wiredTiger.cache.bytes read into cache", want: 4096, wantOK: true},
+		{path: "host"},
+		{path: "opcounters"},
+		{path: "opcounters.delete"},
+		{path: "count.value"},
+		{path: "missing.insert"},
+	}
+	for _, tt := range tests {
+		got, ok := lookupInt64(doc, tt.path)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("lookupInt64(%q): got %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestDiffServerSnapshots(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after map[string]int64
+		want          []ServerStatDelta
+	}{
+		{
+			name:   "report order",
+			before: map[string]int64{"collStats.count": 10, "serverStatus.opcounters.insert": 5, "dbStats.objects": 10},
+			after:  map[string]int64{"collStats.count": 30, "serverStatus.opcounters.insert": 25, "dbStats.objects": 30},
+			want: []ServerStatDelta{
+				{Name: "serverStatus.opcounters.insert", Before: 5, After: 25, Delta: 20},
+				{Name: "dbStats.objects", Before: 10, After: 30, Delta: 20},
+				{Name: "collStats.count", Before: 10, After: 30, Delta: 20},
+			},
+		},
+		{
+			name:   "negative delta",
+			before: map[string]int64{"collStats.size": 100},
+			after:  map[string]int64{"collStats.size": 40},
+			want:   []ServerStatDelta{{Name: "collStats.size", Before: 100, After: 40, Delta: -60}},
+		},
+		{
+			name:   "metric missing from a snapshot",
+			before: map[string]int64{"serverStatus.opcounters.query": 1, "collStats.count": 10},
+			after:  map[string]int64{"serverStatus.opcounters.query": 4},
+			want:   []ServerStatDelta{{Name: "serverStatus.opcounters.query", Before: 1, After: 4, Delta: 3}},
+		},
+		{
+			name:   "unknown metric",
+			before: map[string]int64{"serverStatus.uptime": 1},
+			after:  map[string]int64{"serverStatus.uptime": 2},
+		},
+		{
+			name:   "empty snapshots",
+			before: map[string]int64{},
+			after:  map[string]int64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffServerSnapshots(&ServerSnapshot{Values: tt.before}, &ServerSnapshot{Values: tt.after})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mongoperf/internal/client/query"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sink kinds .
+const (
+	InfluxSinkKind = "influx"
+	StatsDSinkKind = "statsd"
+)
+
+// MetricsSink receives the statistics of every interval of a run.
+type MetricsSink interface {
+	Write(*IntervalSnapshot) error
+	Close() error
+}
+
+// NewMetricsSink returns the sink of the provided kind writing to target.
+func NewMetricsSink(kind, target string) (MetricsSink, error) {
+	switch kind {
+	case InfluxSinkKind:
+		return NewInfluxSink(target)
+	case StatsDSinkKind:
+		return NewStatsDSink(target, "mongoperf")
+	}
+	return nil, fmt.Errorf("sink not supported: %v", kind)
+}
+
+// ParseSink parses a sink flag value formatted as kind=target.
+func ParseSink(s string) (MetricsSink, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("sink must be formatted as kind=target: %v", s)
+	}
+	return NewMetricsSink(parts[0], parts[1])
+}
+
+// udpMaxPacket is the maximum size of a UDP packet which is not fragmented.
+const udpMaxPacket = 1432
+
+// writePackets writes the lines separated by line breaks, batched
+// in packets no larger than udpMaxPacket. A longer line is sent alone.
+func writePackets(w io.Writer, lines []string) error {
+	var packet bytes.Buffer
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > udpMaxPacket {
+			if _, err := w.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	if packet.Len() == 0 {
+		return nil
+	}
+	_, err := w.Write(packet.Bytes())
+	return err
+}
+
+// IntervalQueryStats holds the statistics of a query for one interval.
+type IntervalQueryStats struct {
+	Name   string
+	Action string
+	Ops    int64
+	Errors int64
+	Mean   time.Duration
+	P50    time.Duration
+	P95    time.Duration
+	P99    time.Duration
+	Max    time.Duration
+}
+
+// IntervalSnapshot holds the statistics of the queries
+// completed during one interval.
+type IntervalSnapshot struct {
+	Time     time.Time
+	Interval time.Duration
+	Queries  []IntervalQueryStats
+}
+
+type intervalQuery struct {
+	action  string
+	errors  int64
+	latency *Histogram
+}
+
+// IntervalReporter is an Observer which sends a snapshot
+// of the results of every interval to its sinks.
+type IntervalReporter struct {
+	interval time.Duration
+	sinks    []MetricsSink
+	logger   *logrus.Logger
+
+	mu      sync.Mutex
+	queries map[string]*intervalQuery
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewIntervalReporter .
+func NewIntervalReporter(interval time.Duration, logger *logrus.Logger, sinks ...MetricsSink) *IntervalReporter {
+	return &IntervalReporter{
+		interval: interval,
+		sinks:    sinks,
+		logger:   logger,
+		queries:  make(map[string]*intervalQuery),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// WorkerStarted implements the Observer interface.
+func (r *IntervalReporter) WorkerStarted(id int) {}
+
+// WorkerStopped implements the Observer interface.
+func (r *IntervalReporter) WorkerStopped(id int) {}
+
+// Observe implements the Observer interface.
+func (r *IntervalReporter) Observe(result *query.Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name := *result.Definition.Name
+	q, ok := r.queries[name]
+	if !ok {
+		q = &intervalQuery{action: string(*result.Definition.Action), latency: NewHistogram()}
+		r.queries[name] = q
+	}
+	q.latency.Record(result.Latency())
+	if result.Error != nil {
+		q.errors++
+	}
+}
+
+// Start sends a snapshot to the sinks every interval until Stop is called.
+func (r *IntervalReporter) Start() {
+	go func() {
+		defer close(r.doneCh)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case t := <-ticker.C:
+				r.flush(t)
+			case <-r.stopCh:
+				r.flush(time.Now())
+				return
+			}
+		}
+	}()
+}
+
+// Stop sends the last snapshot. The sinks are left
+// open, and must be closed by their creator.
+func (r *IntervalReporter) Stop() {
+	close(r.stopCh)
+	<-r.doneCh
+}
+
+func (r *IntervalReporter) flush(t time.Time) {
+	snapshot := r.snapshot(t)
+	for _, s := range r.sinks {
+		if err := s.Write(snapshot); err != nil {
+			r.logger.Errorf("writing to sink: %v", err)
+		}
+	}
+}
+
+// snapshot returns the statistics of the current
+// interval and starts a new one.
+func (r *IntervalReporter) snapshot(t time.Time) *IntervalSnapshot {
+	r.mu.Lock()
+	queries := r.queries
+	r.queries = make(map[string]*intervalQuery)
+	r.mu.Unlock()
+
+	snapshot := &IntervalSnapshot{Time: t, Interval: r.interval}
+	for name, q := range queries {
+		snapshot.Queries = append(snapshot.Queries, IntervalQueryStats{
+			Name:   name,
+			Action: q.action,
+			Ops:    q.latency.Count,
+			Errors: q.errors,
+			Mean:   q.latency.Mean(),
+			P50:    q.latency.Percentile(50),
+			P95:    q.latency.Percentile(95),
+			P99:    q.latency.Percentile(99),
+			Max:    q.latency.Max,
+		})
+	}
+	sort.Slice(snapshot.Queries, func(i, j int) bool { return snapshot.Queries[i].Name < snapshot.Queries[j].Name })
+	return snapshot
+}
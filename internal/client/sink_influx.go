@@ -0,0 +1,106 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// InfluxSink writes interval snapshots using the InfluxDB line protocol.
+//
+// The target is either a file path, a udp://host:port address
+// or an http(s) write endpoint URL such as
+// http://localhost:8086/write?db=mongoperf.
+// Over UDP, lines are batched in packets small enough not to be fragmented.
+type InfluxSink struct {
+	w      io.WriteCloser
+	udp    bool
+	url    string
+	client *http.Client
+}
+
+// NewInfluxSink .
+func NewInfluxSink(target string) (*InfluxSink, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return &InfluxSink{url: target, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	case "udp":
+		conn, err := net.Dial("udp", u.Host)
+		if err != nil {
+			return nil, err
+		}
+		return &InfluxSink{w: conn, udp: true}, nil
+	case "", "file":
+		path := target
+		if u.Scheme == "file" {
+			path = u.Path
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return &InfluxSink{w: f}, nil
+	}
+	return nil, fmt.Errorf("influx target scheme not supported: %v", u.Scheme)
+}
+
+// Write implements the MetricsSink interface.
+func (s *InfluxSink) Write(snapshot *IntervalSnapshot) error {
+	lines := make([]string, 0, len(snapshot.Queries))
+	for _, q := range snapshot.Queries {
+		lines = append(lines, fmt.Sprintf("mongoperf,query=%v,action=%v ops=%di,errors=%di,mean_ms=%v,p50_ms=%v,p95_ms=%v,p99_ms=%v,max_ms=%v %d",
+			influxEscape(q.Name), influxEscape(q.Action), q.Ops, q.Errors,
+			durationMillis(q.Mean), durationMillis(q.P50), durationMillis(q.P95), durationMillis(q.P99), durationMillis(q.Max),
+			snapshot.Time.UnixNano()))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	if s.udp {
+		return writePackets(s.w, lines)
+	}
+	body := []byte(strings.Join(lines, "\n") + "\n")
+	if s.client != nil {
+		return s.post(body)
+	}
+	_, err := s.w.Write(body)
+	return err
+}
+
+func (s *InfluxSink) post(body []byte) error {
+	resp, err := s.client.Post(s.url, "text/plain; charset=utf-8", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("influx write: unexpected status %v", resp.Status)
+	}
+	return nil
+}
+
+// Close implements the MetricsSink interface.
+func (s *InfluxSink) Close() error {
+	if s.w == nil {
+		return nil
+	}
+	return s.w.Close()
+}
+
+// influxEscape escapes a tag value.
+func influxEscape(v string) string {
+	if v == "" {
+		return "none"
+	}
+	return strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`).Replace(v)
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+)
+
+var statsdInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_\-]`)
+
+// StatsDSink sends interval snapshots to a StatsD server over UDP.
+// Operations and errors are sent as counters, latencies as gauges
+// in milliseconds.
+type StatsDSink struct {
+	conn   net.Conn
+	prefix string
+}
+
+// NewStatsDSink .
+func NewStatsDSink(addr, prefix string) (*StatsDSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsDSink{conn: conn, prefix: prefix}, nil
+}
+
+// Write implements the MetricsSink interface.
+func (s *StatsDSink) Write(snapshot *IntervalSnapshot) error {
+	var lines []string
+	for _, q := range snapshot.Queries {
+		name := s.prefix + "." + statsdInvalidChars.ReplaceAllString(q.Name, "_")
+		lines = append(lines,
+			fmt.Sprintf("%v.ops:%d|c", name, q.Ops),
+			fmt.Sprintf("%v.errors:%d|c", name, q.Errors),
+			fmt.Sprintf("%v.latency.mean:%.3f|g", name, durationMillis(q.Mean)),
+			fmt.Sprintf("%v.latency.p50:%.3f|g", name, durationMillis(q.P50)),
+			fmt.Sprintf("%v.latency.p95:%.3f|g", name, durationMillis(q.P95)),
+			fmt.Sprintf("%v.latency.p99:%.3f|g", name, durationMillis(q.P99)),
+			fmt.Sprintf("%v.latency.max:%.3f|g", name, durationMillis(q.Max)),
+		)
+	}
+	return writePackets(s.conn, lines)
+}
+
+// Close implements the MetricsSink interface.
+func (s *StatsDSink) Close() error {
+	return s.conn.Close()
+}
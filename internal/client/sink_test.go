@@ -0,0 +1,155 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInfluxEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "", want: "none"},
+		{in: "find", want: "find"},
+		{in: "find by id", want: `find\ by\ id`},
+		{in: "a,b=c", want: `a\,b\=c`},
+	}
+	for _, tt := range tests {
+		if got := influxEscape(tt.in); got != tt.want {
+			t.Errorf("influxEscape(%q): got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInfluxSinkWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mongoperf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "metrics.lp")
+
+	s, err := NewInfluxSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := &IntervalSnapshot{
+		Time: time.Unix(10, 5),
+		Queries: []IntervalQueryStats{
+			{Name: "find by id", Action: "Find", Ops: 12, Errors: 1, Mean: 2 * time.Millisecond,
+				P50: time.Millisecond, P95: 3 * time.Millisecond, P99: 4 * time.Millisecond, Max: 5500 * time.Microsecond},
+			{Name: "a,b=c", Ops: 3},
+		},
+	}
+	if err := s.Write(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&IntervalSnapshot{Time: time.Unix(20, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `mongoperf,query=find\ by\ id,action=Find ops=12i,errors=1i,mean_ms=2,p50_ms=1,p95_ms=3,p99_ms=4,max_ms=5.5 10000000005
+mongoperf,query=a\,b\=c,action=none ops=3i,errors=0i,mean_ms=0,p50_ms=0,p95_ms=0,p99_ms=0,max_ms=0 10000000005
+`
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+type packetRecorder struct {
+	packets []string
+}
+
+func (r *packetRecorder) Write(b []byte) (int, error) {
+	r.packets = append(r.packets, string(b))
+	return len(b), nil
+}
+
+func TestWritePackets(t *testing.T) {
+	line := strings.Repeat("x", 716)
+	long := strings.Repeat("y", udpMaxPacket+10)
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{name: "empty"},
+		{name: "single packet", lines: []string{"a", "b"}, want: []string{"a\nb"}},
+		{
+			// two lines and their separator fill a packet exactly
+			name:  "split at the limit",
+			lines: []string{line[1:], line, "c"},
+			want:  []string{line[1:] + "\n" + line, "c"},
+		},
+		{name: "line longer than a packet", lines: []string{"a", long, "b"}, want: []string{"a", long, "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r packetRecorder
+			if err := writePackets(&r, tt.lines); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(r.packets) != fmt.Sprint(tt.want) {
+				t.Errorf("got %d packets %q, want %d packets %q", len(r.packets), r.packets, len(tt.want), tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsDSinkWrite(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s, err := NewStatsDSink(conn.LocalAddr().String(), "mongoperf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var queries []IntervalQueryStats
+	for i := 0; i < 20; i++ {
+		queries = append(queries, IntervalQueryStats{Name: fmt.Sprintf("query %d", i), Ops: int64(i), Mean: time.Millisecond})
+	}
+	if err := s.Write(&IntervalSnapshot{Queries: queries}); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for len(lines) < 7*len(queries) {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read after %d lines: %v", len(lines), err)
+		}
+		if n > udpMaxPacket {
+			t.Errorf("packet of %d bytes", n)
+		}
+		lines = append(lines, strings.Split(string(buf[:n]), "\n")...)
+	}
+	if len(lines) != 7*len(queries) {
+		t.Fatalf("got %d lines, want %d", len(lines), 7*len(queries))
+	}
+	want := []string{
+		"mongoperf.query_0.ops:0|c",
+		"mongoperf.query_0.errors:0|c",
+		"mongoperf.query_0.latency.mean:1.000|g",
+	}
+	if strings.Join(lines[:3], "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", lines[:3], want)
+	}
+}
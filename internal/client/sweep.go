@@ -0,0 +1,435 @@
+package client
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SweepAttributes are the scenario attributes which can be swept.
+// Other keys of a sweep are set as parameters of the scenario.
+var SweepAttributes = []string{"Parallel", "BufferSize", "Repeat", "Rate", "MaxPoolSize", "MinPoolSize"}
+
+// SweepKneeEfficiency is the fraction of the linear throughput gain
+// a step must achieve to be considered as scaling. The knee point
+// is the value after which steps stop scaling.
+const SweepKneeEfficiency = 0.5
+
+// SweepFormats lists the supported sweep report formats.
+var SweepFormats = []string{TextFormat, JSONFormat, CSVFormat, MarkdownFormat}
+
+// ParseSweep parses sweeps formatted as key=value,value.
+func ParseSweep(values []string) (map[string][]string, error) {
+	matrix := make(map[string][]string, len(values))
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("sweep must be formatted as key=value,value: %v", v)
+		}
+		matrix[parts[0]] = strings.Split(parts[1], ",")
+	}
+	return matrix, nil
+}
+
+// ScenarioMatrix returns the Matrix of the scenario, read before its
+// parameters are resolved: the variables referenced by the Matrix are
+// only resolved from the environment and the defaults of the references.
+func ScenarioMatrix(b []byte) (map[string][]string, error) {
+	placeholder := func(string) (string, bool) { return "x", true }
+	out, err := interpolate(string(b), os.LookupEnv, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Matrix map[string][]string `yaml:"Matrix"`
+	}
+	if err := yaml.Unmarshal([]byte(out), &header); err != nil {
+		return nil, err
+	}
+	return header.Matrix, nil
+}
+
+// SweepCombinations returns every combination of the values of the matrix.
+// Keys are sorted by name, the values of the last one varying first.
+func SweepCombinations(matrix map[string][]string) []map[string]string {
+	keys := sweepKeys(matrix)
+	combinations := []map[string]string{{}}
+	for _, k := range keys {
+		var next []map[string]string
+		for _, c := range combinations {
+			for _, v := range matrix[k] {
+				combination := make(map[string]string, len(c)+1)
+				for ck, cv := range c {
+					combination[ck] = cv
+				}
+				combination[k] = v
+				next = append(next, combination)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+func sweepKeys(matrix map[string][]string) []string {
+	keys := make([]string, 0, len(matrix))
+	for k := range matrix {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isSweepAttribute(key string) bool {
+	for _, a := range SweepAttributes {
+		if a == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSweepScenario returns the scenario of a combination of a sweep.
+// Keys naming one of the SweepAttributes override it, the others are
+// set as parameters, overriding the provided ones, and must be
+// referenced by the scenario.
+func ParseSweepScenario(b []byte, set, combination map[string]string) (*Scenario, error) {
+	referenced := referencedVariables(b)
+	params := make(map[string]string, len(set)+len(combination))
+	for k, v := range set {
+		params[k] = v
+	}
+	var attributes []string
+	for k, v := range combination {
+		switch {
+		case isSweepAttribute(k):
+			attributes = append(attributes, k)
+		case referenced[k]:
+			params[k] = v
+		default:
+			return nil, fmt.Errorf("%v is neither a sweepable attribute (%v) nor a parameter referenced by the scenario",
+				k, strings.Join(SweepAttributes, ", "))
+		}
+	}
+	out, resolved, err := InterpolateScenario(b, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// OVERRIDE ATTRIBUTES
+	// the attributes are replaced in the document, so that
+	// they are validated when the scenario is unmarshalled
+	if len(attributes) > 0 {
+		var doc yaml.MapSlice
+		if err := yaml.Unmarshal(out, &doc); err != nil {
+			return nil, err
+		}
+		sort.Strings(attributes)
+		for _, k := range attributes {
+			var v interface{}
+			if err := yaml.Unmarshal([]byte(combination[k]), &v); err != nil {
+				return nil, fmt.Errorf("%v: %v", k, err)
+			}
+			doc = setMapItem(doc, k, v)
+		}
+		if out, err = yaml.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+	c, err := parseScenario(out)
+	if err != nil {
+		return nil, err
+	}
+	if len(resolved) > 0 {
+		c.Params = resolved
+	}
+	return c, nil
+}
+
+func setMapItem(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i, item := range m {
+		if fmt.Sprint(item.Key) == key {
+			m[i].Value = value
+			return m
+		}
+	}
+	return append(m, yaml.MapItem{Key: key, Value: value})
+}
+
+// SweepRow holds the results of the whole run of a combination.
+type SweepRow struct {
+	Values   map[string]string  `json:"values"`
+	Elapsed  time.Duration      `json:"elapsed"`
+	Total    *ReportQueryResult `json:"total"`
+	Breaches []ThresholdBreach  `json:"breaches,omitempty"`
+}
+
+// SweepPoint is a step of a scaling summary.
+type SweepPoint struct {
+	Value float64 `json:"value"`
+	EPS   float64 `json:"eps"`
+	// Speedup is the throughput relative to the first point.
+	Speedup float64 `json:"speedup"`
+	// Efficiency is the speedup relative to the increase of the value.
+	Efficiency float64 `json:"efficiency"`
+}
+
+// SweepScaling is the throughput of the combinations sharing the
+// values of the other keys, ordered by the value of the scaling key.
+type SweepScaling struct {
+	Key    string            `json:"key"`
+	Fixed  map[string]string `json:"fixed,omitempty"`
+	Points []SweepPoint      `json:"points"`
+	// Knee is the index of the point after which steps stop scaling,
+	// -1 when every step scales.
+	Knee int `json:"knee"`
+}
+
+// SweepReport combines the results of every combination of a sweep.
+type SweepReport struct {
+	Kind          string    `json:"kind"`
+	SchemaVersion int       `json:"schemaVersion"`
+	GeneratedAt   time.Time `json:"generatedAt"`
+	Version       string    `json:"version"`
+	URI           string    `json:"uri"`
+
+	Keys    []string        `json:"keys"`
+	Rows    []*SweepRow     `json:"rows"`
+	Scaling []*SweepScaling `json:"scaling,omitempty"`
+}
+
+// NewSweepReport returns the report of the combinations, in the order they
+// were run, with a scaling summary along Parallel when it is swept, or along
+// the first key whose values are numbers otherwise.
+func NewSweepReport(version, uri string, matrix map[string][]string, reports []*Report) *SweepReport {
+	r := &SweepReport{
+		Kind:          SweepReportKind,
+		SchemaVersion: ReportSchemaVersion,
+		GeneratedAt:   time.Now(),
+		Version:       version,
+		URI:           RedactURI(uri),
+		Keys:          sweepKeys(matrix),
+	}
+	combinations := SweepCombinations(matrix)
+	for i, report := range reports {
+		r.Rows = append(r.Rows, &SweepRow{
+			Values:   combinations[i],
+			Elapsed:  report.Elapsed,
+			Total:    report.Total,
+			Breaches: report.Breaches,
+		})
+	}
+	if key, ok := scalingKey(r.Keys, matrix); ok {
+		r.Scaling = newSweepScaling(key, r.Keys, r.Rows)
+	}
+	return r
+}
+
+func scalingKey(keys []string, matrix map[string][]string) (string, bool) {
+	candidates := append([]string{"Parallel"}, keys...)
+	for _, k := range candidates {
+		values, ok := matrix[k]
+		if !ok || len(values) < 2 {
+			continue
+		}
+		numeric := true
+		for _, v := range values {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				numeric = false
+			}
+		}
+		if numeric {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func newSweepScaling(key string, keys []string, rows []*SweepRow) []*SweepScaling {
+	var (
+		groups []*SweepScaling
+		index  = make(map[string]*SweepScaling)
+	)
+	for _, row := range rows {
+		if row.Total == nil {
+			continue
+		}
+		fixed := make(map[string]string)
+		var id []string
+		for _, k := range keys {
+			if k != key {
+				fixed[k] = row.Values[k]
+				id = append(id, k+"="+row.Values[k])
+			}
+		}
+		g, ok := index[strings.Join(id, ",")]
+		if !ok {
+			g = &SweepScaling{Key: key, Fixed: fixed, Knee: -1}
+			index[strings.Join(id, ",")] = g
+			groups = append(groups, g)
+		}
+		v, _ := strconv.ParseFloat(row.Values[key], 64)
+		g.Points = append(g.Points, SweepPoint{Value: v, EPS: row.Total.EPS})
+	}
+	for _, g := range groups {
+		sort.SliceStable(g.Points, func(i, j int) bool { return g.Points[i].Value < g.Points[j].Value })
+		first := g.Points[0]
+		for i := range g.Points {
+			p := &g.Points[i]
+			if first.EPS > 0 {
+				p.Speedup = p.EPS / first.EPS
+			}
+			if first.Value > 0 && p.Value > 0 {
+				p.Efficiency = p.Speedup / (p.Value / first.Value)
+			}
+			if i == 0 || g.Knee >= 0 {
+				continue
+			}
+			if stepEfficiency(g.Points[i-1], *p) < SweepKneeEfficiency {
+				g.Knee = i - 1
+			}
+		}
+	}
+	return groups
+}
+
+// stepEfficiency returns the throughput gain of a step
+// relative to the increase of the value.
+func stepEfficiency(prev, p SweepPoint) float64 {
+	if prev.EPS <= 0 || prev.Value <= 0 || p.Value <= prev.Value {
+		return 0
+	}
+	return (p.EPS/prev.EPS - 1) / (p.Value/prev.Value - 1)
+}
+
+// WriteSweepReport renders the sweep report using the provided format.
+func WriteSweepReport(w io.Writer, r *SweepReport, format string) error {
+	switch format {
+	case TextFormat:
+		return GenerateSweepReport(w, r)
+	case JSONFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case CSVFormat:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(sweepColumns(r)); err != nil {
+			return err
+		}
+		for _, row := range r.Rows {
+			if err := cw.Write(sweepRow(r, row, formatMillis)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case MarkdownFormat:
+		columns := sweepColumns(r)
+		separators := make([]string, len(columns))
+		for i := range separators {
+			separators[i] = "---"
+		}
+		lines := []string{markdownRow(columns), markdownRow(separators)}
+		for _, row := range r.Rows {
+			lines = append(lines, markdownRow(sweepRow(r, row, time.Duration.String)))
+		}
+		_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+		return err
+	}
+	return fmt.Errorf("sweep report format not supported: %v", format)
+}
+
+func sweepColumns(r *SweepReport) []string {
+	return append(append([]string(nil), r.Keys...),
+		"QueryCount", "ErrorCount", "EPS", "P50", "P95", "P99", "Max", "Elapsed", "Breaches")
+}
+
+func sweepRow(r *SweepReport, row *SweepRow, dur func(time.Duration) string) []string {
+	var cells []string
+	for _, k := range r.Keys {
+		cells = append(cells, row.Values[k])
+	}
+	total := row.Total
+	if total == nil {
+		total = &ReportQueryResult{}
+	}
+	return append(cells,
+		strconv.Itoa(total.QueryCount),
+		strconv.Itoa(total.ErrorCount),
+		strconv.FormatFloat(total.EPS, 'f', 2, 64),
+		dur(total.P50),
+		dur(total.P95),
+		dur(total.P99),
+		dur(total.Max),
+		dur(row.Elapsed),
+		strconv.Itoa(len(row.Breaches)),
+	)
+}
+
+// GenerateSweepReport writes a table row per combination,
+// followed by the scaling summary.
+func GenerateSweepReport(w io.Writer, r *SweepReport) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	columns := sweepColumns(r)
+	for i, c := range columns {
+		columns[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintf(tw, "%v\t\n", strings.Join(columns, "\t"))
+	for _, row := range r.Rows {
+		fmt.Fprintf(tw, "%v\t\n", strings.Join(sweepRow(r, row, time.Duration.String), "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, g := range r.Scaling {
+		fmt.Fprintf(w, "\nScaling of %v", g.Key)
+		if len(g.Fixed) > 0 {
+			var fixed []string
+			for _, k := range r.Keys {
+				if v, ok := g.Fixed[k]; ok {
+					fixed = append(fixed, k+"="+v)
+				}
+			}
+			fmt.Fprintf(w, " (%v)", strings.Join(fixed, ", "))
+		}
+		fmt.Fprintln(w, ":")
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "%v\tEPS\tSPEEDUP\tEFFICIENCY\t\n", strings.ToUpper(g.Key))
+		for i, p := range g.Points {
+			flag := ""
+			if i == g.Knee {
+				flag = "KNEE"
+			}
+			fmt.Fprintf(tw, "%v\t%.2f\t%.2fx\t%.0f%%\t%v\n", p.Value, p.EPS, p.Speedup, p.Efficiency*100, flag)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		if g.Knee < 0 {
+			last := g.Points[len(g.Points)-1]
+			fmt.Fprintf(w, "no knee point: throughput still scales at %v=%v\n", g.Key, last.Value)
+			continue
+		}
+		knee, next := g.Points[g.Knee], g.Points[g.Knee+1]
+		fmt.Fprintf(w, "knee point: %v=%v (%.2f ops/s), ", g.Key, knee.Value, knee.EPS)
+		var err error
+		if next.EPS < knee.EPS {
+			_, err = fmt.Fprintf(w, "throughput decreased by %.0f%% at %v=%v\n", (1-next.EPS/knee.EPS)*100, g.Key, next.Value)
+		} else {
+			_, err = fmt.Fprintf(w, "the next step reached %.0f%% of linear scaling\n", stepEfficiency(knee, next)*100)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSweepCombinations(t *testing.T) {
+	got := SweepCombinations(map[string][]string{"Parallel": {"1", "2"}, "LIMIT": {"10", "20", "30"}})
+	if len(got) != 6 {
+		t.Fatalf("SweepCombinations() returned %d combinations, want 6", len(got))
+	}
+	seen := make(map[string]bool)
+	for _, c := range got {
+		seen["LIMIT="+c["LIMIT"]+",Parallel="+c["Parallel"]] = true
+	}
+	if len(seen) != 6 {
+		t.Errorf("SweepCombinations() = %v, want distinct combinations", got)
+	}
+}
+
+func TestNewSweepReportKnee(t *testing.T) {
+	tests := []struct {
+		name string
+		eps  []float64
+		knee int
+	}{
+		{name: "linear", eps: []float64{100, 200, 400, 800}, knee: -1},
+		{name: "flattens", eps: []float64{100, 200, 380, 400}, knee: 2},
+		{name: "flat from the start", eps: []float64{100, 110, 120, 130}, knee: 0},
+		{name: "drops", eps: []float64{100, 200, 150, 400}, knee: 1},
+	}
+	parallel := []string{"1", "2", "4", "8"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []*Report
+			for _, eps := range tt.eps {
+				reports = append(reports, &Report{Total: &ReportQueryResult{EPS: eps}})
+			}
+			r := NewSweepReport("", "", map[string][]string{"Parallel": parallel}, reports)
+			if len(r.Scaling) != 1 {
+				t.Fatalf("Scaling = %v, want a single summary", r.Scaling)
+			}
+			s := r.Scaling[0]
+			if s.Key != "Parallel" || s.Knee != tt.knee {
+				t.Errorf("Scaling = %v with knee %d, want Parallel with knee %d", s.Key, s.Knee, tt.knee)
+			}
+			if p := s.Points[len(s.Points)-1]; p.Speedup != tt.eps[len(tt.eps)-1]/tt.eps[0] {
+				t.Errorf("Speedup = %v, want %v", p.Speedup, tt.eps[len(tt.eps)-1]/tt.eps[0])
+			}
+		})
+	}
+}
+
+func TestNewSweepReportGroups(t *testing.T) {
+	matrix := map[string][]string{"Parallel": {"1", "2"}, "MODE": {"a", "b"}}
+	var reports []*Report
+	for _, c := range SweepCombinations(matrix) {
+		eps := 100.0
+		if c["Parallel"] == "2" {
+			eps = 200
+		}
+		if c["MODE"] == "b" {
+			eps /= 2
+		}
+		reports = append(reports, &Report{Total: &ReportQueryResult{EPS: eps}})
+	}
+	r := NewSweepReport("", "", matrix, reports)
+	if len(r.Scaling) != 2 {
+		t.Fatalf("Scaling has %d groups, want 2", len(r.Scaling))
+	}
+	for _, s := range r.Scaling {
+		var values []float64
+		for _, p := range s.Points {
+			values = append(values, p.Value)
+		}
+		if !reflect.DeepEqual(values, []float64{1, 2}) || s.Points[1].Speedup != 2 {
+			t.Errorf("group %v: points %+v, want a speedup of 2 from 1 to 2", s.Fixed, s.Points)
+		}
+	}
+}
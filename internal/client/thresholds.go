@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"mongoperf/internal/client/query"
+	"time"
+)
+
+// ThresholdBreach describes a metric which exceeded its threshold.
+type ThresholdBreach struct {
+	Query   string `json:"query"`
+	Metric  string `json:"metric"`
+	Limit   string `json:"limit"`
+	Actual  string `json:"actual"`
+	Message string `json:"message"`
+}
+
+// CheckThresholds returns the thresholds of the query which were breached.
+func (r *ReportQueryResult) CheckThresholds() []ThresholdBreach {
+	return checkThresholds(r.Name, r.Thresholds, r)
+}
+
+// CheckReportThresholds returns the query and global thresholds
+// breached during the run.
+func CheckReportThresholds(r *Report) []ThresholdBreach {
+	var breaches []ThresholdBreach
+	for _, res := range r.Results {
+		breaches = append(breaches, res.CheckThresholds()...)
+	}
+	if r.Total != nil {
+		breaches = append(breaches, r.Total.CheckThresholds()...)
+	}
+	return breaches
+}
+
+func checkThresholds(name string, t *query.Thresholds, r *ReportQueryResult) []ThresholdBreach {
+	if t == nil {
+		return nil
+	}
+	var breaches []ThresholdBreach
+	if t.MaxErrors != nil && r.ErrorCount > *t.MaxErrors {
+		breaches = append(breaches, countBreach(name, "error count", r.ErrorCount, *t.MaxErrors))
+	}
+	if t.MaxErrorRate != nil && r.ErrorRate() > *t.MaxErrorRate {
+		actual, limit := r.ErrorRate()*100, *t.MaxErrorRate*100
+		breaches = append(breaches, ThresholdBreach{
+			Query:   name,
+			Metric:  "error rate",
+			Limit:   fmt.Sprintf("%.2f%%", limit),
+			Actual:  fmt.Sprintf("%.2f%%", actual),
+			Message: fmt.Sprintf("error rate %.2f%% exceeds %.2f%% by %.2f points", actual, limit, actual-limit),
+		})
+	}
+	if t.MinOpsPerSec != nil && r.EPS < *t.MinOpsPerSec {
+		actual, limit := r.EPS, *t.MinOpsPerSec
+		breaches = append(breaches, ThresholdBreach{
+			Query:   name,
+			Metric:  "ops/sec",
+			Limit:   fmt.Sprintf("%.2f", limit),
+			Actual:  fmt.Sprintf("%.2f", actual),
+			Message: fmt.Sprintf("ops/sec %.2f is below %.2f by %.2f (%.1f%%)", actual, limit, limit-actual, -percentOver(actual, limit)),
+		})
+	}
+	if t.MaxP95 != nil && r.P95 > *t.MaxP95 {
+		breaches = append(breaches, latencyBreach(name, "p95 latency", r.P95, *t.MaxP95))
+	}
+	if t.MaxP99 != nil && r.P99 > *t.MaxP99 {
+		breaches = append(breaches, latencyBreach(name, "p99 latency", r.P99, *t.MaxP99))
+	}
+	return breaches
+}
+
+func countBreach(name, metric string, actual, limit int) ThresholdBreach {
+	return ThresholdBreach{
+		Query:   name,
+		Metric:  metric,
+		Limit:   fmt.Sprint(limit),
+		Actual:  fmt.Sprint(actual),
+		Message: fmt.Sprintf("%v %d exceeds %d by %d", metric, actual, limit, actual-limit),
+	}
+}
+
+func latencyBreach(name, metric string, actual, limit time.Duration) ThresholdBreach {
+	return ThresholdBreach{
+		Query:   name,
+		Metric:  metric,
+		Limit:   limit.String(),
+		Actual:  actual.String(),
+		Message: fmt.Sprintf("%v %v exceeds %v by %v (%.1f%%)", metric, actual, limit, actual-limit, percentOver(float64(actual), float64(limit))),
+	}
+}
+
+// percentOver returns by how many percent actual exceeds limit.
+func percentOver(actual, limit float64) float64 {
+	if limit == 0 {
+		return 0
+	}
+	return (actual - limit) / limit * 100
+}
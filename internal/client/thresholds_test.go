@@ -0,0 +1,98 @@
+package client
+
+import (
+	"mongoperf/internal/client/query"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckThresholds(t *testing.T) {
+	maxErrors := 1
+	maxErrorRate := 0.01
+	minOpsPerSec := 100.0
+	maxP95 := 10 * time.Millisecond
+	maxP99 := 20 * time.Millisecond
+	tests := []struct {
+		name       string
+		thresholds *query.Thresholds
+		result     ReportQueryResult
+		want       []ThresholdBreach
+	}{
+		{
+			name:   "no thresholds",
+			result: ReportQueryResult{QueryCount: 10, ErrorCount: 10},
+		},
+		{
+			name:       "held",
+			thresholds: &query.Thresholds{MaxErrors: &maxErrors, MaxErrorRate: &maxErrorRate, MinOpsPerSec: &minOpsPerSec, MaxP95: &maxP95, MaxP99: &maxP99},
+			result:     ReportQueryResult{QueryCount: 100, ErrorCount: 1, EPS: 100, P95: maxP95, P99: maxP99},
+		},
+		{
+			name:       "error count",
+			thresholds: &query.Thresholds{MaxErrors: &maxErrors},
+			result:     ReportQueryResult{QueryCount: 100, ErrorCount: 3},
+			want: []ThresholdBreach{
+				{Query: "q", Metric: "error count", Limit: "1", Actual: "3", Message: "error count 3 exceeds 1 by 2"},
+			},
+		},
+		{
+			name:       "error rate",
+			thresholds: &query.Thresholds{MaxErrorRate: &maxErrorRate},
+			result:     ReportQueryResult{QueryCount: 100, ErrorCount: 3},
+			want: []ThresholdBreach{
+				{Query: "q", Metric: "error rate", Limit: "1.00%", Actual: "3.00%", Message: "error rate 3.00% exceeds 1.00% by 2.00 points"},
+			},
+		},
+		{
+			name:       "error rate without queries",
+			thresholds: &query.Thresholds{MaxErrorRate: &maxErrorRate},
+		},
+		{
+			name:       "ops/sec",
+			thresholds: &query.Thresholds{MinOpsPerSec: &minOpsPerSec},
+			result:     ReportQueryResult{EPS: 75},
+			want: []ThresholdBreach{
+				{Query: "q", Metric: "ops/sec", Limit: "100.00", Actual: "75.00", Message: "ops/sec 75.00 is below 100.00 by 25.00 (25.0%)"},
+			},
+		},
+		{
+			name:       "latencies",
+			thresholds: &query.Thresholds{MaxP95: &maxP95, MaxP99: &maxP99},
+			result:     ReportQueryResult{P95: 15 * time.Millisecond, P99: 30 * time.Millisecond},
+			want: []ThresholdBreach{
+				{Query: "q", Metric: "p95 latency", Limit: "10ms", Actual: "15ms", Message: "p95 latency 15ms exceeds 10ms by 5ms (50.0%)"},
+				{Query: "q", Metric: "p99 latency", Limit: "20ms", Actual: "30ms", Message: "p99 latency 30ms exceeds 20ms by 10ms (50.0%)"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.result
+			r.Name = "q"
+			r.Thresholds = tt.thresholds
+			if got := r.CheckThresholds(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckThresholds() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckReportThresholds(t *testing.T) {
+	maxErrors := 0
+	r := &Report{
+		Results: []*ReportQueryResult{
+			{Name: "a", QueryCount: 10, ErrorCount: 1, Thresholds: &query.Thresholds{MaxErrors: &maxErrors}},
+			{Name: "b", QueryCount: 10, ErrorCount: 1},
+		},
+		Total: &ReportQueryResult{Name: "total", QueryCount: 20, ErrorCount: 2, Thresholds: &query.Thresholds{MaxErrors: &maxErrors}},
+	}
+	breaches := CheckReportThresholds(r)
+	var queries []string
+	for _, b := range breaches {
+		queries = append(queries, b.Query)
+	}
+	if want := []string{"a", "total"}; !reflect.DeepEqual(queries, want) {
+		t.Errorf("CheckReportThresholds() breached %v, want %v", queries, want)
+	}
+}
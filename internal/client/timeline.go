@@ -0,0 +1,114 @@
+package client
+
+import "time"
+
+// TimelineInterval is the width of a timeline slot.
+const TimelineInterval = time.Second
+
+// maxTimelineSlots is the number of slots past which the interval
+// of a timeline is doubled, merging its adjacent slots.
+const maxTimelineSlots = 1800
+
+// TimelineSlot holds the results completed during one interval of a run.
+type TimelineSlot struct {
+	Ops        int           `json:"ops"`
+	Errors     int           `json:"errors"`
+	LatencySum time.Duration `json:"latencySum"`
+	LatencyMax time.Duration `json:"latencyMax"`
+}
+
+// LatencyAvg returns the average latency of the slot.
+func (s TimelineSlot) LatencyAvg() time.Duration {
+	if s.Ops == 0 {
+		return 0
+	}
+	return time.Duration(int64(s.LatencySum) / int64(s.Ops))
+}
+
+// Timeline records results in fixed intervals since the start of a run.
+// The interval is doubled as needed to keep at most maxTimelineSlots slots.
+type Timeline struct {
+	Start    time.Time
+	Interval time.Duration
+	Slots    []TimelineSlot
+}
+
+// NewTimeline .
+func NewTimeline(start time.Time, interval time.Duration) *Timeline {
+	return &Timeline{Start: start, Interval: interval}
+}
+
+// Record adds a result completed at the provided time.
+func (t *Timeline) Record(at time.Time, dur time.Duration, err error) {
+	i := t.slot(at)
+	for i >= maxTimelineSlots {
+		t.coarsen()
+		i = t.slot(at)
+	}
+	for len(t.Slots) <= i {
+		t.Slots = append(t.Slots, TimelineSlot{})
+	}
+	slot := &t.Slots[i]
+	slot.Ops++
+	slot.LatencySum += dur
+	if dur > slot.LatencyMax {
+		slot.LatencyMax = dur
+	}
+	if err != nil {
+		slot.Errors++
+	}
+}
+
+// Merge adds the slots of o, which must share the same start.
+// The finer of the two timelines is coarsened to the interval of the other.
+func (t *Timeline) Merge(o *Timeline) {
+	for t.Interval < o.Interval {
+		t.coarsen()
+	}
+	slots := o.SlotsAt(t.Interval)
+	for len(t.Slots) < len(slots) {
+		t.Slots = append(t.Slots, TimelineSlot{})
+	}
+	for i, slot := range slots {
+		t.Slots[i].add(slot)
+	}
+}
+
+// SlotsAt returns the slots merged to the provided interval,
+// which must be the interval of the timeline doubled zero or more times.
+func (t *Timeline) SlotsAt(interval time.Duration) []TimelineSlot {
+	c := &Timeline{Start: t.Start, Interval: t.Interval, Slots: t.Slots}
+	for c.Interval < interval {
+		c.coarsen()
+	}
+	return c.Slots
+}
+
+// slot returns the index of the slot of the provided time.
+func (t *Timeline) slot(at time.Time) int {
+	i := int(at.Sub(t.Start) / t.Interval)
+	if i < 0 {
+		return 0
+	}
+	return i
+}
+
+// coarsen doubles the interval, merging the slots by pairs.
+func (t *Timeline) coarsen() {
+	slots := make([]TimelineSlot, (len(t.Slots)+1)/2)
+	for i, slot := range t.Slots {
+		slots[i/2].add(slot)
+	}
+	t.Slots = slots
+	t.Interval *= 2
+}
+
+// add merges o into the slot.
+func (s *TimelineSlot) add(o TimelineSlot) {
+	s.Ops += o.Ops
+	s.Errors += o.Errors
+	s.LatencySum += o.LatencySum
+	if o.LatencyMax > s.LatencyMax {
+		s.LatencyMax = o.LatencyMax
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimelineRecord(t *testing.T) {
+	start := time.Now()
+	tl := NewTimeline(start, time.Second)
+	tl.Record(start.Add(-time.Second), time.Millisecond, nil)
+	tl.Record(start.Add(1500*time.Millisecond), 3*time.Millisecond, errors.New("failed"))
+	tl.Record(start.Add(1900*time.Millisecond), time.Millisecond, nil)
+	want := []TimelineSlot{
+		{Ops: 1, LatencySum: time.Millisecond, LatencyMax: time.Millisecond},
+		{Ops: 2, Errors: 1, LatencySum: 4 * time.Millisecond, LatencyMax: 3 * time.Millisecond},
+	}
+	if len(tl.Slots) != len(want) {
+		t.Fatalf("got %d slots, want %d", len(tl.Slots), len(want))
+	}
+	for i := range want {
+		if tl.Slots[i] != want[i] {
+			t.Errorf("slot %d: got %+v, want %+v", i, tl.Slots[i], want[i])
+		}
+	}
+}
+
+func TestTimelineCap(t *testing.T) {
+	start := time.Now()
+	tl := NewTimeline(start, time.Second)
+	// one result per second for 2.5 times the maximum number of slots
+	n := maxTimelineSlots*5/2 + 1
+	for i := 0; i < n; i++ {
+		tl.Record(start.Add(time.Duration(i)*time.Second), time.Millisecond, nil)
+	}
+	if tl.Interval != 4*time.Second {
+		t.Errorf("got interval %v, want 4s", tl.Interval)
+	}
+	if len(tl.Slots) > maxTimelineSlots {
+		t.Errorf("got %d slots, want at most %d", len(tl.Slots), maxTimelineSlots)
+	}
+	ops := 0
+	for i, slot := range tl.Slots {
+		ops += slot.Ops
+		if i < len(tl.Slots)-1 && slot.Ops != 4 {
+			t.Errorf("slot %d: got %d ops, want 4", i, slot.Ops)
+		}
+	}
+	if ops != n {
+		t.Errorf("got %d ops, want %d", ops, n)
+	}
+}
+
+func TestTimelineMerge(t *testing.T) {
+	start := time.Now()
+	fine := NewTimeline(start, time.Second)
+	for i := 0; i < 4; i++ {
+		fine.Record(start.Add(time.Duration(i)*time.Second), time.Millisecond, nil)
+	}
+	coarse := NewTimeline(start, 2*time.Second)
+	coarse.Record(start.Add(5*time.Second), 2*time.Millisecond, nil)
+
+	total := NewTimeline(start, time.Second)
+	total.Merge(fine)
+	total.Merge(coarse)
+	if total.Interval != 2*time.Second {
+		t.Fatalf("got interval %v, want 2s", total.Interval)
+	}
+	want := []int{2, 2, 1}
+	if len(total.Slots) != len(want) {
+		t.Fatalf("got %d slots, want %d", len(total.Slots), len(want))
+	}
+	for i, ops := range want {
+		if total.Slots[i].Ops != ops {
+			t.Errorf("slot %d: got %d ops, want %d", i, total.Slots[i].Ops, ops)
+		}
+	}
+	if got := fine.SlotsAt(total.Interval); len(got) != 2 || got[0].Ops != 2 || len(fine.Slots) != 4 {
+		t.Errorf("SlotsAt: got %+v, fine timeline %d slots", got, len(fine.Slots))
+	}
+}
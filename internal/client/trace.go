@@ -0,0 +1,158 @@
+package client
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mongoperf/internal/client/query"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Trace formats .
+const (
+	JSONLTraceFormat = "jsonl"
+	CSVTraceFormat   = "csv"
+)
+
+// traceBufferSize is the number of records queued before
+// the TraceWriter starts dropping them.
+const traceBufferSize = 1 << 16
+
+var traceColumns = []string{"query", "worker", "iteration", "start", "durationNs", "changes", "error"}
+
+// TraceRecord describes a single completed query.
+type TraceRecord struct {
+	Query     string    `json:"query"`
+	Worker    int       `json:"worker"`
+	Iteration int       `json:"iteration"`
+	Start     time.Time `json:"start"`
+	Duration  int64     `json:"durationNs"`
+	Changes   int       `json:"changes"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// TraceWriter is an Observer writing every result to a file.
+//
+// Results are queued and written from a separate goroutine through
+// a buffered writer, so tracing never blocks the run. When the queue
+// is full, records are dropped and counted.
+type TraceWriter struct {
+	w       *bufio.Writer
+	closer  io.Closer
+	encode  func(*TraceRecord) error
+	flush   func() error
+	records chan *TraceRecord
+	done    chan error
+	dropped int64
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewTraceWriter starts writing records to wc using the provided format.
+func NewTraceWriter(wc io.WriteCloser, format string) (*TraceWriter, error) {
+	t := &TraceWriter{
+		w:       bufio.NewWriterSize(wc, 1<<20),
+		closer:  wc,
+		records: make(chan *TraceRecord, traceBufferSize),
+		done:    make(chan error, 1),
+	}
+	switch format {
+	case JSONLTraceFormat:
+		enc := json.NewEncoder(t.w)
+		t.encode = func(r *TraceRecord) error { return enc.Encode(r) }
+	case CSVTraceFormat:
+		cw := csv.NewWriter(t.w)
+		if err := cw.Write(traceColumns); err != nil {
+			return nil, err
+		}
+		t.encode = func(r *TraceRecord) error {
+			err := cw.Write([]string{
+				r.Query,
+				strconv.Itoa(r.Worker),
+				strconv.Itoa(r.Iteration),
+				r.Start.Format(time.RFC3339Nano),
+				strconv.FormatInt(r.Duration, 10),
+				strconv.Itoa(r.Changes),
+				r.Error,
+			})
+			return err
+		}
+		// the csv writer shares the buffered writer, which
+		// is flushed once all the records are written
+		t.flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return nil, fmt.Errorf("trace format not supported: %v", format)
+	}
+	go t.run()
+	return t, nil
+}
+
+func (t *TraceWriter) run() {
+	var err error
+	for r := range t.records {
+		if err != nil {
+			continue
+		}
+		err = t.encode(r)
+	}
+	if err == nil && t.flush != nil {
+		err = t.flush()
+	}
+	if err == nil {
+		err = t.w.Flush()
+	}
+	t.done <- err
+}
+
+// WorkerStarted implements the Observer interface.
+func (t *TraceWriter) WorkerStarted(id int) {}
+
+// WorkerStopped implements the Observer interface.
+func (t *TraceWriter) WorkerStopped(id int) {}
+
+// Observe implements the Observer interface.
+func (t *TraceWriter) Observe(result *query.Result) {
+	r := &TraceRecord{
+		Query:     *result.Definition.Name,
+		Worker:    result.WorkerID,
+		Iteration: result.Iteration,
+		Start:     result.Start,
+		Duration:  int64(result.End.Sub(result.Start)),
+		Changes:   result.TotalChange,
+	}
+	if result.Error != nil {
+		r.Error = result.Error.Error()
+	}
+	select {
+	case t.records <- r:
+	default:
+		atomic.AddInt64(&t.dropped, 1)
+	}
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (t *TraceWriter) Dropped() int64 {
+	return atomic.LoadInt64(&t.dropped)
+}
+
+// Close writes the queued records and closes the underlying writer.
+// Subsequent calls return the error of the first one.
+func (t *TraceWriter) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.records)
+		t.closeErr = <-t.done
+		if err := t.closer.Close(); t.closeErr == nil {
+			t.closeErr = err
+		}
+	})
+	return t.closeErr
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"mongoperf/internal/client/query"
+	"strings"
+	"testing"
+	"time"
+)
+
+type traceBuffer struct {
+	bytes.Buffer
+	err error
+}
+
+func (b *traceBuffer) Write(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.Buffer.Write(p)
+}
+
+func (b *traceBuffer) Close() error { return nil }
+
+func TestTraceWriter(t *testing.T) {
+	def := &query.Definition{Name: query.String("find")}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		format  string
+		results int
+		err     error
+		lines   int
+	}{
+		{format: JSONLTraceFormat, results: traceBufferSize * 2, lines: traceBufferSize * 2},
+		{format: CSVTraceFormat, results: traceBufferSize * 2, lines: traceBufferSize*2 + 1},
+		{format: CSVTraceFormat, results: 0, lines: 1},
+		{format: JSONLTraceFormat, results: 10, err: errors.New("disk full")},
+		{format: CSVTraceFormat, results: 10, err: errors.New("disk full")},
+	}
+	for _, tt := range tests {
+		var b traceBuffer
+		b.err = tt.err
+		w, err := NewTraceWriter(&b, tt.format)
+		if err != nil {
+			t.Fatalf("NewTraceWriter(%v) error = %v", tt.format, err)
+		}
+		for i := 0; i < tt.results; i++ {
+			w.Observe(&query.Result{Definition: def, Iteration: i, Start: start, End: start.Add(time.Millisecond)})
+		}
+		err = w.Close()
+		if tt.err != nil {
+			if err != tt.err {
+				t.Errorf("%v: Close() error = %v, want %v", tt.format, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: Close() error = %v", tt.format, err)
+		}
+		// records dropped when the queue is full are counted
+		if got := strings.Count(b.String(), "\n") + int(w.Dropped()); got != tt.lines {
+			t.Errorf("%v: %d results written as %d lines and %d dropped, want %d lines",
+				tt.format, tt.results, got-int(w.Dropped()), w.Dropped(), tt.lines)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"mongoperf/internal/client/query"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v2"
+)
+
+// ValidationProblem is a problem found in a scenario file.
+type ValidationProblem struct {
+	Line    int
+	Query   string
+	Message string
+}
+
+func (p ValidationProblem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", p.Line)
+	}
+	if p.Query != "" {
+		fmt.Fprintf(&b, "query %v: ", p.Query)
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ValidateScenarioFile returns the problems found in the
+// scenario file using the provided filepath. The parameters
+// override the Params of the scenario.
+func ValidateScenarioFile(fp string, set map[string]string) ([]ValidationProblem, error) {
+	filename, err := filepath.Abs(fp)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateScenario(b, set), nil
+}
+
+// ValidateScenario validates the scenario against its JSON Schema,
+// then parses it and builds the Querier of every query, without
+// connecting to MongoDB. Unlike ParseScenario, which stops at the
+// first error, every problem found is returned.
+//
+// Line numbers are located from the YAML nodes of the keys
+// named by the errors, and are 0 when unknown.
+// Variables which cannot be replaced are reported, and other problems
+// found on their lines are left out, as they depend on their value.
+func ValidateScenario(b []byte, set map[string]string) []ValidationProblem {
+	out, _, err := InterpolateScenario(b, set)
+	verrs, ok := err.(VariableErrors)
+	if err != nil && !ok {
+		return yamlProblems(err)
+	}
+	var problems []ValidationProblem
+	undefined := make(map[int]bool)
+	for _, e := range verrs {
+		undefined[e.Line] = true
+		problems = append(problems, ValidationProblem{
+			Line:    e.Line,
+			Message: fmt.Sprintf("variable %v %v", e.Name, e.Message),
+		})
+	}
+	for _, p := range validateScenario(out) {
+		if !undefined[p.Line] {
+			problems = append(problems, p)
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems
+}
+
+func validateScenario(b []byte) []ValidationProblem {
+	var doc interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return yamlProblems(err)
+	}
+	var raw struct {
+		Queries []yaml.MapSlice `yaml:"Queries"`
+	}
+	// type errors are reported by the schema
+	yaml.Unmarshal(b, &raw)
+	lines := strings.Split(string(b), "\n")
+	loc := newLocator(b)
+	sectionStart, sectionEnd := loc.queriesSection()
+	names := make([]string, len(raw.Queries))
+	for i, item := range raw.Queries {
+		names[i] = fmt.Sprintf("#%d", i+1)
+		for _, kv := range item {
+			if fmt.Sprint(kv.Key) == "Name" && kv.Value != nil {
+				names[i] = fmt.Sprint(kv.Value)
+			}
+		}
+	}
+
+	// VALIDATE SCHEMA
+	var (
+		problems      []ValidationProblem
+		invalidHeader bool
+		invalidQuery  = make(map[int]bool)
+	)
+	for _, e := range ScenarioSchema().Validate(doc) {
+		m := queryPathRe.FindStringSubmatch(e.Path)
+		if m == nil {
+			invalidHeader = true
+			problems = append(problems, ValidationProblem{
+				Line:    loc.line(e.Path, 0),
+				Message: e.Error(),
+			})
+			continue
+		}
+		i, _ := strconv.Atoi(m[1])
+		invalidQuery[i] = true
+		p := ValidationProblem{
+			Line:    loc.queryKeyLine(i, m[2]),
+			Message: SchemaError{Path: m[2], Message: e.Message}.Error(),
+		}
+		if i < len(names) {
+			p.Query = names[i]
+		}
+		problems = append(problems, p)
+	}
+
+	// PARSE SCENARIO
+	// queries are replaced by a placeholder, keeping line numbers,
+	// so that problems are reported for both the scenario and its queries
+	header := b
+	if len(raw.Queries) > 0 && sectionStart >= 0 {
+		headerLines := append([]string(nil), lines...)
+		headerLines[sectionStart] = "Queries: [{Name: placeholder}]"
+		for i := sectionStart + 1; i < sectionEnd; i++ {
+			headerLines[i] = ""
+		}
+		header = []byte(strings.Join(headerLines, "\n"))
+	}
+	if _, err := parseScenario(header); err != nil && !invalidHeader {
+		if _, ok := err.(*yaml.TypeError); ok {
+			problems = append(problems, yamlProblems(err)...)
+		} else {
+			problems = append(problems, ValidationProblem{
+				Line:    loc.line(firstWord(err.Error()), 0),
+				Message: err.Error(),
+			})
+		}
+	}
+
+	// PARSE QUERIES
+	definitions := make([]*query.Definition, len(raw.Queries))
+	for i, item := range raw.Queries {
+		if invalidQuery[i] {
+			continue
+		}
+		start := loc.queryLine(i)
+		name := names[i]
+		out, err := yaml.Marshal(item)
+		if err != nil {
+			problems = append(problems, ValidationProblem{Line: start, Query: name, Message: err.Error()})
+			continue
+		}
+		var def query.Definition
+		if err := yaml.Unmarshal(out, &def); err != nil {
+			// lines of the errors are relative to the query
+			for _, p := range yamlProblems(err) {
+				p.Line = loc.queryKeyLine(i, firstWord(p.Message))
+				p.Query = name
+				problems = append(problems, p)
+			}
+			continue
+		}
+		definitions[i] = &def
+	}
+
+	// BUILD QUERIERS
+	used := make(map[string]int)
+	for i, def := range definitions {
+		if def == nil {
+			continue
+		}
+		start := loc.queryLine(i)
+		if prev, ok := used[*def.Name]; ok {
+			problems = append(problems, ValidationProblem{
+				Line:    start,
+				Query:   *def.Name,
+				Message: fmt.Sprintf("Name already used by the query on line %d", prev),
+			})
+		} else {
+			used[*def.Name] = start
+		}
+		if def.Action == nil {
+			problems = append(problems, ValidationProblem{Line: start, Query: *def.Name, Message: "Action must not be empty"})
+			continue
+		}
+		if _, err := query.NewQuerier(def); err != nil {
+			problems = append(problems, ValidationProblem{
+				Line:    loc.queryKeyLine(i, "Meta"),
+				Query:   *def.Name,
+				Message: err.Error(),
+			})
+			continue
+		}
+		if err := query.CheckMeta(def); err != nil {
+			problems = append(problems, metaProblems(loc, i, *def.Name, err)...)
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems
+}
+
+var yamlLineRe = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// yamlProblems returns a problem per error reported by the yaml package.
+func yamlProblems(err error) []ValidationProblem {
+	messages := []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	if terr, ok := err.(*yaml.TypeError); ok {
+		messages = terr.Errors
+	}
+	var problems []ValidationProblem
+	for _, msg := range messages {
+		p := ValidationProblem{Message: msg}
+		if m := yamlLineRe.FindStringSubmatch(msg); m != nil {
+			p.Line, _ = strconv.Atoi(m[1])
+			p.Message = m[2]
+		}
+		problems = append(problems, p)
+	}
+	return problems
+}
+
+var invalidKeysRe = regexp.MustCompile(`'(.*)' has invalid keys: (.*)$`)
+
+// metaProblems returns a problem per error reported by mapstructure,
+// located on the first unknown key when possible.
+func metaProblems(loc *locator, i int, name string, err error) []ValidationProblem {
+	messages := []string{err.Error()}
+	if merr, ok := err.(*mapstructure.Error); ok {
+		messages = merr.Errors
+	}
+	var problems []ValidationProblem
+	for _, msg := range messages {
+		line := loc.queryKeyLine(i, "Meta")
+		if m := invalidKeysRe.FindStringSubmatch(msg); m != nil {
+			keys := strings.Split(m[2], ", ")
+			field := "Meta"
+			if m[1] != "" {
+				field += "." + m[1]
+			}
+			line = loc.queryKeyLine(i, field+"."+keys[0])
+			msg = fmt.Sprintf("%v: unknown key(s): %v", field, m[2])
+		}
+		problems = append(problems, ValidationProblem{Line: line, Query: name, Message: msg})
+	}
+	return problems
+}
+
+var queryPathRe = regexp.MustCompile(`^Queries\[(\d+)\]\.?(.*)$`)
+
+func firstWord(s string) string {
+	if i := strings.IndexAny(s, " :"); i > 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateScenarioLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		line    int
+		message string
+	}{
+		{
+			name: "block style",
+			in: `Database: db
+Collection: c
+Queries:
+  - Name: a
+    Action: Find
+    Meta:
+      Options:
+        Limitt: 1
+`,
+			line:    8,
+			message: "Meta.Options.Limitt",
+		},
+		{
+			name: "flow style queries",
+			in: `Database: db
+Collection: c
+Queries: [{Name: a, Action: Find, Meta: {Filter: {}}},
+  {Name: b, Action: Find, Meta: {Optons: 1}}]
+`,
+			line:    4,
+			message: "Meta.Optons",
+		},
+		{
+			name: "alias",
+			in: `Database: db
+Collection: c
+Queries:
+  - &q
+    Name: a
+    Action: Find
+    Meta: {Filter: {}}
+  - *q
+`,
+			line:    8,
+			message: "Name already used",
+		},
+		{
+			name: "merge key",
+			in: `Database: db
+Collection: c
+Queries:
+  - &q
+    Name: a
+    Action: Find
+    Meta: {Filter: {}}
+  - <<: *q
+    Name: b
+    Meta: {Filtr: {}}
+`,
+			line:    10,
+			message: "Meta.Filtr",
+		},
+		{
+			name: "header after flow style queries",
+			in: `Database: db
+Collection: c
+Queries: [{Name: a, Action: Find,
+  Meta: {Filter: {}}}]
+MaxPoolSize: 2
+MinPoolSize: 5
+`,
+			line:    6,
+			message: "MinPoolSize",
+		},
+		{
+			name: "flow style document",
+			in: `{Database: db, Collection: c,
+  Parallel: 0,
+  Queries: [{Name: a, Action: Find, Meta: {Filter: {}}}]}
+`,
+			line:    2,
+			message: "Parallel",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := ValidateScenario([]byte(tt.in), nil)
+			if len(problems) != 1 {
+				t.Fatalf("ValidateScenario() = %v, want a single problem", problems)
+			}
+			p := problems[0]
+			if p.Line != tt.line || !strings.Contains(p.String(), tt.message) {
+				t.Errorf("ValidateScenario() = %v, want line %d: %v", p, tt.line, tt.message)
+			}
+		})
+	}
+}